@@ -0,0 +1,342 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/burdzwastaken/regolint/internal/callgraph"
+	"github.com/burdzwastaken/regolint/internal/changes"
+	"github.com/burdzwastaken/regolint/internal/config"
+	"github.com/burdzwastaken/regolint/internal/evaluator"
+	"github.com/burdzwastaken/regolint/internal/fix"
+	"github.com/burdzwastaken/regolint/internal/model"
+	"github.com/burdzwastaken/regolint/internal/nolint"
+	"github.com/burdzwastaken/regolint/internal/transformer"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// packageInput holds the transformed files of a loaded package.
+type packageInput struct {
+	pkg      *packages.Package
+	fixPkg   *fix.Package
+	fileCtxs []*model.CodeContext
+
+	// unresolved lists the well-known interfaces pkg does not depend on.
+	unresolved []string
+}
+
+// evalTask evaluates one file or package and returns its filtered violations.
+type evalTask func(ctx context.Context) ([]model.Violation, error)
+
+// analyze transforms and evaluates all packages, and the module they form, on
+// a pool of workers sized by performance.parallelism. Violations are returned
+// sorted by position and rule regardless of scheduling. When changed is not
+// nil, only violations in new code are returned.
+func analyze(ctx context.Context, pkgs []*packages.Package, eval *evaluator.Evaluator, cfg *config.Config, changed *changes.Set) ([]model.Violation, error) {
+	workers := cfg.Performance.Parallelism
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	graph, opts, err := analyzeProgram(ctx, pkgs, eval, cfg)
+	if err != nil {
+		return nil, err
+	}
+	ctx = callgraph.NewContext(ctx, graph)
+
+	inputs := make([]*packageInput, len(pkgs))
+	err = forEach(workers, len(pkgs), func(i int) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		input, err := transformPackage(pkgs[i], cfg, opts...)
+		inputs[i] = input
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	warnUnresolvedInterfaces(inputs, cfg)
+
+	modCtx := buildModuleContext(modulePath(pkgs), inputs)
+	if dumpInputs(inputs, modCtx) {
+		return nil, nil
+	}
+
+	tasks := evalTasks(eval, inputs, modCtx, cfg, changed)
+
+	results := make([][]model.Violation, len(tasks))
+	err = forEach(workers, len(tasks), func(i int) error {
+		violations, err := tasks[i](ctx)
+		results[i] = violations
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	violations := slices.Concat(results...)
+	sortViolations(violations)
+	return violations, nil
+}
+
+// evalTasks returns a task for every file and package, and one for the module.
+func evalTasks(eval *evaluator.Evaluator, inputs []*packageInput, modCtx *model.ModuleContext, cfg *config.Config, changed *changes.Set) []evalTask {
+	var tasks []evalTask
+	for _, input := range inputs {
+		for _, codeCtx := range input.fileCtxs {
+			tasks = append(tasks, func(ctx context.Context) ([]model.Violation, error) {
+				return evaluateFile(ctx, eval, input.fixPkg, codeCtx, cfg, changed)
+			})
+		}
+		tasks = append(tasks, func(ctx context.Context) ([]model.Violation, error) {
+			return evaluatePackage(ctx, eval, input, cfg, changed)
+		})
+	}
+	return append(tasks, func(ctx context.Context) ([]model.Violation, error) {
+		return evaluateModule(ctx, eval, inputs, modCtx, cfg, changed)
+	})
+}
+
+// forEach calls fn for every index in [0, n) using up to workers goroutines.
+// It returns the error of the lowest failing index so failures are reported
+// deterministically.
+func forEach(workers, n int, fn func(i int) error) error {
+	errs := make([]error, n)
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(workers, n) {
+		wg.Go(func() {
+			for i := range indexes {
+				errs[i] = fn(i)
+			}
+		})
+	}
+
+	for i := range n {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transformPackage transforms the files of pkg using the syntax trees and
+// type information loaded with it, as the golangci-lint plugin does, and the
+// results of whole-program analysis passed in opts.
+func transformPackage(pkg *packages.Package, cfg *config.Config, opts ...transformer.Option) (*packageInput, error) {
+	for _, e := range pkg.Errors {
+		if e.Kind == packages.ParseError {
+			return nil, fmt.Errorf("parsing %s: %w", pkg.PkgPath, e)
+		}
+	}
+
+	pass := &analysis.Pass{
+		Fset:       pkg.Fset,
+		Files:      pkg.Syntax,
+		Pkg:        pkg.Types,
+		TypesInfo:  pkg.TypesInfo,
+		TypesSizes: pkg.TypesSizes,
+	}
+
+	trans := transformer.New(pass, pkg.PkgPath, opts...)
+	input := &packageInput{
+		pkg: pkg,
+		fixPkg: &fix.Package{
+			Fset:      pkg.Fset,
+			Files:     pkg.Syntax,
+			Types:     pkg.Types,
+			TypesInfo: pkg.TypesInfo,
+		},
+	}
+
+	for _, file := range pkg.Syntax {
+		filePath := pkg.Fset.Position(file.Pos()).Filename
+		if cfg.ShouldSkip(filePath) || cfg.ExcludeGenerated && ast.IsGenerated(file) {
+			continue
+		}
+
+		input.fileCtxs = append(input.fileCtxs, trans.Transform(file, filePath))
+	}
+	input.unresolved = trans.UnresolvedInterfaces()
+
+	return input, nil
+}
+
+// warnUnresolvedInterfaces warns about the well-known interfaces that no
+// analyzed package depends on, so types were never checked against them. The
+// default list is only reported with --debug, as most code uses few of them.
+func warnUnresolvedInterfaces(inputs []*packageInput, cfg *config.Config) {
+	if !*debug && slices.Equal(cfg.Analysis.Interfaces, config.DefaultInterfaces) {
+		return
+	}
+
+	for _, name := range cfg.Analysis.Interfaces {
+		resolved := false
+		for _, input := range inputs {
+			resolved = resolved || input.pkg.Types != nil && !slices.Contains(input.unresolved, name)
+		}
+		if !resolved {
+			fmt.Fprintf(os.Stderr, "warning: interface %s from analysis.interfaces is not imported by any analyzed package\n", name)
+		}
+	}
+}
+
+func evaluateFile(ctx context.Context, eval *evaluator.Evaluator, fixPkg *fix.Package, codeCtx *model.CodeContext, cfg *config.Config, changed *changes.Set) ([]model.Violation, error) {
+	violations, err := eval.Evaluate(ctx, codeCtx)
+	if err != nil {
+		return nil, fmt.Errorf("evaluating %s: %w", codeCtx.FilePath, err)
+	}
+	fixPkg.Lower(violations, codeCtx.FilePath)
+
+	return keepChangedLines(changed, filterViolations(violations, codeCtx, cfg)), nil
+}
+
+// evaluatePackage evaluates package-scoped rules against all files of a package.
+// Violations naming no file of the package are reported without their fix,
+// and only when on a changed line in new-code mode.
+func evaluatePackage(ctx context.Context, eval *evaluator.Evaluator, input *packageInput, cfg *config.Config, changed *changes.Set) ([]model.Violation, error) {
+	pkgCtx := transformer.BuildPackageContext(input.fileCtxs)
+	if pkgCtx == nil {
+		return nil, nil
+	}
+
+	pkgViolations, err := eval.EvaluatePackage(ctx, pkgCtx)
+	if err != nil {
+		return nil, fmt.Errorf("evaluating package %s: %w", input.pkg.PkgPath, err)
+	}
+	input.fixPkg.Lower(pkgViolations, "")
+
+	// Package violations carry the base file name of the node they anchor to,
+	// so group them by file to restore full paths and apply nolint directives.
+	byFile := make(map[string]*model.CodeContext, len(input.fileCtxs))
+	for _, codeCtx := range input.fileCtxs {
+		byFile[filepath.Base(codeCtx.FilePath)] = codeCtx
+	}
+
+	grouped := make(map[*model.CodeContext][]model.Violation)
+	var unanchored []model.Violation
+	for _, v := range pkgViolations {
+		codeCtx, ok := byFile[filepath.Base(v.Position.File)]
+		if !ok {
+			unanchored = append(unanchored, v)
+			continue
+		}
+		grouped[codeCtx] = append(grouped[codeCtx], v)
+	}
+
+	var violations []model.Violation
+	for _, codeCtx := range input.fileCtxs {
+		violations = append(violations, filterViolations(grouped[codeCtx], codeCtx, cfg)...)
+	}
+
+	return append(keepTouchedDecls(changed, input.pkg, violations), keepChangedLines(changed, filterUnanchored(unanchored, cfg))...), nil
+}
+
+// dumpInputs prints the policy inputs for --dry-run and --debug. It reports
+// whether evaluation should be skipped.
+func dumpInputs(inputs []*packageInput, modCtx *model.ModuleContext) bool {
+	if !*dryRun && !*debug {
+		return false
+	}
+
+	for _, input := range inputs {
+		for _, codeCtx := range input.fileCtxs {
+			dumpInput(codeCtx.FilePath, codeCtx)
+		}
+		if pkgCtx := transformer.BuildPackageContext(input.fileCtxs); pkgCtx != nil {
+			dumpInput("package "+input.pkg.PkgPath, pkgCtx)
+		}
+	}
+	dumpInput("module "+modCtx.ModulePath, modCtx)
+
+	return *dryRun
+}
+
+func dumpInput(label string, input any) {
+	data, _ := json.MarshalIndent(input, "", "  ")
+	if *dryRun {
+		fmt.Printf("=== %s ===\n%s\n\n", label, data)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "DEBUG: %s\n%s\n", label, data)
+}
+
+// filterViolations drops disabled and nolint-suppressed violations reported
+// against a file and applies configured severity overrides.
+func filterViolations(violations []model.Violation, codeCtx *model.CodeContext, cfg *config.Config) []model.Violation {
+	var filtered []model.Violation
+	for _, v := range violations {
+		if !cfg.IsRuleDisabled(v.Rule) {
+			v.Position.File = codeCtx.FilePath
+			v.Fix = anchorFix(v.Fix, filepath.Dir(codeCtx.FilePath))
+			v.Severity = cfg.GetSeverity(v.Rule, v.Severity)
+			filtered = append(filtered, v)
+		}
+	}
+
+	return nolint.FilterModelViolations(filtered, codeCtx.Nolints)
+}
+
+// filterUnanchored applies the configuration to violations that name no
+// analyzed file. Their fixes are dropped, as without a file their edits
+// cannot be resolved or applied.
+func filterUnanchored(violations []model.Violation, cfg *config.Config) []model.Violation {
+	var filtered []model.Violation
+	for _, v := range violations {
+		if !cfg.IsRuleDisabled(v.Rule) {
+			v.Severity = cfg.GetSeverity(v.Rule, v.Severity)
+			v.Fix = nil
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// anchorFix returns a copy of f whose edits name their files by path in dir
+// rather than by base name.
+func anchorFix(f *model.Fix, dir string) *model.Fix {
+	if f == nil || len(f.Edits) == 0 {
+		return f
+	}
+
+	anchored := &model.Fix{Description: f.Description, Edits: slices.Clone(f.Edits)}
+	for i, e := range anchored.Edits {
+		e.Position.File = filepath.Join(dir, filepath.Base(e.Position.File))
+		if e.End != nil {
+			end := *e.End
+			end.File = e.Position.File
+			e.End = &end
+		}
+		anchored.Edits[i] = e
+	}
+	return anchored
+}
+
+func sortViolations(violations []model.Violation) {
+	slices.SortStableFunc(violations, func(a, b model.Violation) int {
+		return cmp.Or(
+			cmp.Compare(a.Position.File, b.Position.File),
+			cmp.Compare(a.Position.Line, b.Position.Line),
+			cmp.Compare(a.Position.Column, b.Position.Column),
+			cmp.Compare(a.Rule, b.Rule),
+			cmp.Compare(a.Message, b.Message),
+		)
+	})
+}
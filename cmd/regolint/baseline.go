@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"os"
+
+	"github.com/burdzwastaken/regolint/internal/baseline"
+	"github.com/burdzwastaken/regolint/internal/model"
+	"golang.org/x/tools/go/packages"
+)
+
+// writeBaseline records violations in a baseline file at path.
+func writeBaseline(path string, violations []model.Violation, files baseline.Files) error {
+	b := baseline.New(path, violations, files)
+	if err := b.Write(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d violation(s) as %d baseline entries to %s\n", len(violations), len(b.Entries), path)
+	return nil
+}
+
+// applyBaseline drops the violations recorded in the baseline at path and
+// warns about entries that no longer match, so the baseline can be shrunk.
+func applyBaseline(path string, violations []model.Violation, files baseline.Files) ([]model.Violation, error) {
+	b, err := baseline.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	kept, stale := b.Filter(violations, files)
+	for _, e := range stale {
+		symbol := ""
+		if e.Symbol != "" {
+			symbol = " in " + e.Symbol
+		}
+		fmt.Fprintf(os.Stderr, "warning: stale baseline entry: %s %s%s matched %d fewer violation(s): %s\n",
+			e.Rule, e.File, symbol, e.Count, e.Source)
+	}
+	if len(stale) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: %d stale baseline entries; rerun with --write-baseline %s to shrink it\n", len(stale), path)
+	}
+
+	return kept, nil
+}
+
+// loadedFiles returns the syntax trees of pkgs by file path, so baselines
+// fingerprint violations without parsing their files again.
+func loadedFiles(pkgs []*packages.Package) baseline.Files {
+	files := baseline.Files{Syntax: make(map[string]*ast.File)}
+	for _, pkg := range pkgs {
+		files.Fset = pkg.Fset
+		for _, file := range pkg.Syntax {
+			files.Syntax[pkg.Fset.File(file.Pos()).Name()] = file
+		}
+	}
+	return files
+}
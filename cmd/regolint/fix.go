@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/burdzwastaken/regolint/internal/fix"
+	"github.com/burdzwastaken/regolint/internal/model"
+)
+
+// runFix applies the fixes suggested by violations and reports the violations
+// left over. With --diff it prints the changes instead of writing them, and
+// reports failure when there are changes to apply or violations without a
+// fix.
+func runFix(violations []model.Violation, format string) error {
+	result, err := fix.Apply(violations)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range result.Skipped {
+		fmt.Fprintf(os.Stderr, "warning: skipping fix for %s at %s:%d:%d: %s\n",
+			s.Violation.Rule, s.Violation.Position.File,
+			s.Violation.Position.Line, s.Violation.Position.Column, s.Reason)
+	}
+
+	if *showDiff {
+		for _, f := range result.Files {
+			name := displayPath(f.Path)
+			if err := f.WriteDiff(os.Stdout, "a/"+name, "b/"+name); err != nil {
+				return err
+			}
+		}
+		if len(result.Files) > 0 || len(result.Remaining) > 0 {
+			return ErrViolationsFound
+		}
+		return nil
+	}
+
+	if err := result.Write(); err != nil {
+		return err
+	}
+	if len(result.Fixed) > 0 {
+		fmt.Fprintf(os.Stderr, "fixed %d violation(s) in %d file(s)\n", len(result.Fixed), len(result.Files))
+	}
+
+	return report(result.Remaining, format)
+}
+
+// displayPath returns path relative to the working directory when it lies
+// beneath it, so diffs can be applied with patch -p1 or git apply.
+func displayPath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
@@ -6,20 +6,14 @@ import (
 	"errors"
 	"flag"
 	"fmt"
-	"go/parser"
-	"go/token"
 	"os"
-	"path/filepath"
-	"slices"
 	"strings"
 
-	"github.com/bmatcuk/doublestar/v4"
+	"github.com/burdzwastaken/regolint/internal/baseline"
+	"github.com/burdzwastaken/regolint/internal/config"
 	"github.com/burdzwastaken/regolint/internal/evaluator"
 	"github.com/burdzwastaken/regolint/internal/model"
-	"github.com/burdzwastaken/regolint/internal/nolint"
 	"github.com/burdzwastaken/regolint/internal/output"
-	"github.com/burdzwastaken/regolint/internal/transformer"
-	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/packages"
 )
 
@@ -30,20 +24,37 @@ var (
 )
 
 var (
-	policyDir   = flag.String("policy-dir", "./policies", "directory containing .rego policy files")
-	disabled    = flag.String("disabled", "", "comma-separated list of rule IDs to disable")
-	exclude     = flag.String("exclude", "", "comma-separated list of file patterns to exclude")
-	format      = flag.String("format", "text", "output format: text, json, sarif")
-	debug       = flag.Bool("debug", false, "enable debug output")
-	dryRun      = flag.Bool("dry-run", false, "show input without evaluating")
-	showVersion = flag.Bool("version", false, "print version and exit")
+	configPath   = flag.String("config", "", "path to config file (default: discover .regolint.yml up to the module root)")
+	policyDir    = flag.String("policy-dir", "", "directory containing .rego policy files (overrides config)")
+	disabled     = flag.String("disabled", "", "comma-separated list of rule IDs to disable")
+	exclude      = flag.String("exclude", "", "comma-separated list of file patterns to exclude")
+	format       = flag.String("format", "text", "output format: text, json, sarif")
+	parallelism  = flag.Int("parallelism", 0, "number of files and packages evaluated concurrently (overrides config)")
+	timeout      = flag.String("timeout", "", "evaluation timeout per file and per package, e.g. 30s (overrides config)")
+	budget       = flag.String("budget", "", "wall-clock budget for the whole run, e.g. 10m (overrides config)")
+	debug        = flag.Bool("debug", false, "enable debug output")
+	dryRun       = flag.Bool("dry-run", false, "show input without evaluating")
+	showDiff     = flag.Bool("diff", false, "with fix, print a unified diff instead of writing files")
+	baselineIn   = flag.String("baseline", "", "baseline file of violations to ignore")
+	baselineOut  = flag.String("write-baseline", "", "write the current violations to a baseline file and exit")
+	newFromRev   = flag.String("new-from-rev", "", "report only violations in code changed since a git revision")
+	newFromPatch = flag.String("new-from-patch", "", "report only violations in code changed by a unified diff file")
+	showVersion  = flag.Bool("version", false, "print version and exit")
 )
 
 // ErrViolationsFound is returned when policy violations are detected.
 var ErrViolationsFound = errors.New("violations found")
 
+// fixMode is set when regolint is invoked as "regolint fix".
+var fixMode bool
+
 func main() {
-	flag.Parse()
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "fix" {
+		fixMode = true
+		args = args[1:]
+	}
+	_ = flag.CommandLine.Parse(args)
 
 	if *showVersion {
 		fmt.Printf("regolint %s (commit: %s, built: %s)\n", version, commit, date)
@@ -51,7 +62,7 @@ func main() {
 	}
 
 	if flag.NArg() == 0 {
-		fmt.Fprintln(os.Stderr, "usage: regolint [flags] <packages>")
+		fmt.Fprintln(os.Stderr, "usage: regolint [fix] [flags] <packages>")
 		os.Exit(1)
 	}
 
@@ -65,41 +76,95 @@ func main() {
 }
 
 func run() error {
-	policies, err := loadPolicies(*policyDir)
+	cfg, err := resolveConfig()
 	if err != nil {
-		return fmt.Errorf("loading policies: %w", err)
+		return err
 	}
 
-	if len(policies) == 0 {
-		fmt.Fprintln(os.Stderr, "warning: no policies found")
+	runBudget, err := cfg.GetBudget()
+	if err != nil {
+		return err
+	}
+
+	eval, err := newEvaluator(cfg)
+	if err != nil {
+		return err
+	}
+
+	if eval == nil {
+		fmt.Fprintf(os.Stderr, "warning: no policies found in %s\n", cfg.Policies.Directory)
 		return nil
 	}
 
-	eval, err := evaluator.New(policies)
+	ctx := context.Background()
+	if runBudget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, runBudget)
+		defer cancel()
+	}
+
+	changed, err := loadChanges(ctx)
 	if err != nil {
-		return fmt.Errorf("creating evaluator: %w", err)
+		return fmt.Errorf("computing changed lines: %w", err)
 	}
 
 	pkgPatterns := flag.Args()
-	pkgs, err := loadPackages(pkgPatterns)
+	pkgs, err := loadPackages(ctx, pkgPatterns)
 	if err != nil {
 		return fmt.Errorf("loading packages: %w", err)
 	}
 
-	disabledRules := parseList(*disabled)
-	excludePatterns := parseList(*exclude)
+	allViolations, err := analyze(ctx, pkgs, eval, cfg, changed)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("run exceeded budget of %s: %w", runBudget, err)
+		}
+		return err
+	}
 
-	var allViolations []model.Violation
+	return finish(allViolations, loadedFiles(pkgs), cfg.Output.Format)
+}
 
-	for _, pkg := range pkgs {
-		violations, err := analyzePackage(pkg, eval, pkg.PkgPath, disabledRules, excludePatterns)
-		if err != nil {
+// resolveConfig loads the configuration. A --policy-dir that does not exist
+// is an error rather than an empty run.
+func resolveConfig() (*config.Config, error) {
+	overrides := flagOverrides()
+	cfg, err := config.Resolve(*configPath, overrides)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+
+	if overrides.PolicyDir != "" {
+		if _, err := os.Stat(overrides.PolicyDir); err != nil {
+			return nil, fmt.Errorf("policy directory: %w", err)
+		}
+	}
+	return cfg, nil
+}
+
+// finish writes or applies a baseline, then fixes or reports violations.
+func finish(allViolations []model.Violation, files baseline.Files, format string) error {
+	if *baselineOut != "" {
+		return writeBaseline(*baselineOut, allViolations, files)
+	}
+
+	if *baselineIn != "" {
+		var err error
+		if allViolations, err = applyBaseline(*baselineIn, allViolations, files); err != nil {
 			return err
 		}
-		allViolations = append(allViolations, violations...)
 	}
 
-	if err := outputResults(allViolations); err != nil {
+	if fixMode {
+		return runFix(allViolations, format)
+	}
+
+	return report(allViolations, format)
+}
+
+// report prints violations and signals whether any were found.
+func report(allViolations []model.Violation, format string) error {
+	if err := outputResults(allViolations, format); err != nil {
 		return err
 	}
 	if len(allViolations) > 0 {
@@ -108,36 +173,53 @@ func run() error {
 	return nil
 }
 
-func loadPolicies(dir string) (map[string]string, error) {
-	policies := make(map[string]string)
+// newEvaluator compiles the configured policies. It returns a nil Evaluator
+// when no policies are found.
+func newEvaluator(cfg *config.Config) (*evaluator.Evaluator, error) {
+	evalTimeout, err := cfg.GetTimeout()
+	if err != nil {
+		return nil, err
+	}
 
-	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			return nil
-		}
-		if filepath.Ext(path) != ".rego" {
-			return nil
-		}
-		if strings.HasSuffix(path, "_test.rego") {
-			return nil
-		}
+	policies, err := cfg.LoadPolicies()
+	if err != nil {
+		return nil, fmt.Errorf("loading policies: %w", err)
+	}
 
-		content, err := os.ReadFile(filepath.Clean(path))
-		if err != nil {
-			return fmt.Errorf("reading %s: %w", path, err)
-		}
-		policies[path] = string(content)
-		return nil
-	})
+	if len(policies) == 0 {
+		return nil, nil
+	}
 
-	if os.IsNotExist(err) {
-		return policies, nil
+	eval, err := evaluator.New(policies, evaluator.WithTimeout(evalTimeout))
+	if err != nil {
+		return nil, fmt.Errorf("creating evaluator: %w", err)
 	}
+	return eval, nil
+}
 
-	return policies, err
+// flagOverrides collects the flags set on the command line, which take
+// precedence over the config file.
+func flagOverrides() config.Overrides {
+	var overrides config.Overrides
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "policy-dir":
+			overrides.PolicyDir = *policyDir
+		case "disabled":
+			overrides.Disabled = parseList(*disabled)
+		case "exclude":
+			overrides.Exclude = parseList(*exclude)
+		case "format":
+			overrides.Format = *format
+		case "parallelism":
+			overrides.Parallelism = *parallelism
+		case "timeout":
+			overrides.Timeout = *timeout
+		case "budget":
+			overrides.Budget = *budget
+		}
+	})
+	return overrides
 }
 
 func parseList(s string) []string {
@@ -154,90 +236,22 @@ func parseList(s string) []string {
 	return result
 }
 
-func loadPackages(patterns []string) ([]*packages.Package, error) {
+func loadPackages(ctx context.Context, patterns []string) ([]*packages.Package, error) {
 	cfg := &packages.Config{
-		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
-			packages.NeedTypes | packages.NeedTypesInfo,
+		Context: ctx,
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports |
+			packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo |
+			packages.NeedTypesSizes | packages.NeedModule,
 	}
 	return packages.Load(cfg, patterns...)
 }
 
-func analyzePackage(pkg *packages.Package, eval *evaluator.Evaluator, modulePath string, disabledRules, excludePatterns []string) ([]model.Violation, error) {
-	var violations []model.Violation
-
-	fset := token.NewFileSet()
-
-	pass := &analysis.Pass{
-		Fset: fset,
-		Pkg:  pkg.Types,
-	}
-
-	trans := transformer.New(pass, modulePath)
-
-	for _, filePath := range pkg.GoFiles {
-		if shouldSkip(filePath, excludePatterns) {
-			continue
-		}
-
-		file, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
-		if err != nil {
-			return nil, fmt.Errorf("parsing %s: %w", filePath, err)
-		}
-
-		codeCtx := trans.Transform(file, filePath)
-
-		if *dryRun {
-			data, _ := json.MarshalIndent(codeCtx, "", "  ")
-			fmt.Printf("=== %s ===\n%s\n\n", filePath, data)
-			continue
-		}
-
-		if *debug {
-			data, _ := json.MarshalIndent(codeCtx, "", "  ")
-			fmt.Fprintf(os.Stderr, "DEBUG: %s\n%s\n", filePath, data)
-		}
-
-		fileViolations, err := eval.Evaluate(context.Background(), codeCtx)
-		if err != nil {
-			return nil, fmt.Errorf("evaluating %s: %w", filePath, err)
-		}
-
-		var filtered []model.Violation
-		for _, v := range fileViolations {
-			if !isDisabled(v.Rule, disabledRules) {
-				v.Position.File = filePath
-				filtered = append(filtered, v)
-			}
-		}
-
-		filtered = nolint.FilterModelViolations(filtered, codeCtx.Nolints)
-
-		violations = append(violations, filtered...)
-	}
-
-	return violations, nil
-}
-
-func shouldSkip(filePath string, patterns []string) bool {
-	for _, pattern := range patterns {
-		matched, err := doublestar.Match(pattern, filePath)
-		if err == nil && matched {
-			return true
-		}
-	}
-	return false
-}
-
-func isDisabled(rule string, disabled []string) bool {
-	return slices.Contains(disabled, rule)
-}
-
-func outputResults(violations []model.Violation) error {
+func outputResults(violations []model.Violation, format string) error {
 	if len(violations) == 0 {
 		return nil
 	}
 
-	switch *format {
+	switch format {
 	case "json":
 		data, err := json.MarshalIndent(violations, "", "  ")
 		if err != nil {
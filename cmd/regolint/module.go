@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/burdzwastaken/regolint/internal/changes"
+	"github.com/burdzwastaken/regolint/internal/config"
+	"github.com/burdzwastaken/regolint/internal/evaluator"
+	"github.com/burdzwastaken/regolint/internal/model"
+	"github.com/burdzwastaken/regolint/internal/transformer"
+	"golang.org/x/tools/go/packages"
+)
+
+// modulePath returns the path of the main module of the loaded packages, or
+// "" outside module mode.
+func modulePath(pkgs []*packages.Package) string {
+	for _, pkg := range pkgs {
+		if pkg.Module != nil && pkg.Module.Main {
+			return pkg.Module.Path
+		}
+	}
+	for _, pkg := range pkgs {
+		if pkg.Module != nil {
+			return pkg.Module.Path
+		}
+	}
+	return ""
+}
+
+// buildModuleContext summarizes all analyzed packages for module rules.
+func buildModuleContext(modPath string, inputs []*packageInput) *model.ModuleContext {
+	files := make([][]*model.CodeContext, 0, len(inputs))
+	for _, input := range inputs {
+		files = append(files, input.fileCtxs)
+	}
+	return transformer.BuildModuleContext(modPath, files)
+}
+
+// evaluateModule evaluates module-scoped rules once against all packages.
+// Violations name their file by path; each is filtered like the violations
+// of the package that file belongs to. Violations naming no analyzed file are
+// reported without their fix, and only when on a changed line in new-code
+// mode.
+func evaluateModule(ctx context.Context, eval *evaluator.Evaluator, inputs []*packageInput, modCtx *model.ModuleContext, cfg *config.Config, changed *changes.Set) ([]model.Violation, error) {
+	modViolations, err := eval.EvaluateModule(ctx, modCtx)
+	if err != nil {
+		return nil, fmt.Errorf("evaluating module: %w", err)
+	}
+
+	type owner struct {
+		input   *packageInput
+		codeCtx *model.CodeContext
+	}
+	byPath := make(map[string]owner)
+	for _, input := range inputs {
+		for _, codeCtx := range input.fileCtxs {
+			byPath[codeCtx.FilePath] = owner{input, codeCtx}
+		}
+	}
+
+	grouped := make(map[*model.CodeContext][]model.Violation)
+	var unanchored []model.Violation
+	for _, v := range modViolations {
+		o, ok := byPath[v.Position.File]
+		if !ok {
+			unanchored = append(unanchored, v)
+			continue
+		}
+		grouped[o.codeCtx] = append(grouped[o.codeCtx], v)
+	}
+
+	violations := keepChangedLines(changed, filterUnanchored(unanchored, cfg))
+	for _, input := range inputs {
+		var pkgViolations []model.Violation
+		for _, codeCtx := range input.fileCtxs {
+			input.fixPkg.Lower(grouped[codeCtx], codeCtx.FilePath)
+			pkgViolations = append(pkgViolations, filterViolations(grouped[codeCtx], codeCtx, cfg)...)
+		}
+		violations = append(violations, keepTouchedDecls(changed, input.pkg, pkgViolations)...)
+	}
+
+	return violations, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"go/ast"
+	"path/filepath"
+
+	"github.com/burdzwastaken/regolint/internal/changes"
+	"github.com/burdzwastaken/regolint/internal/model"
+	"golang.org/x/tools/go/packages"
+)
+
+// loadChanges computes the changed lines selected by --new-from-rev or
+// --new-from-patch, which restrict reporting to new code. It returns nil when
+// neither is set.
+func loadChanges(ctx context.Context) (*changes.Set, error) {
+	switch {
+	case *newFromRev != "" && *newFromPatch != "":
+		return nil, errors.New("--new-from-rev and --new-from-patch are mutually exclusive")
+	case *newFromRev != "":
+		return changes.FromRev(ctx, *newFromRev)
+	case *newFromPatch != "":
+		return changes.FromPatch(ctx, *newFromPatch)
+	}
+	return nil, nil
+}
+
+// keepChangedLines drops violations outside the changed lines, including
+// those that name no file, unless changed is nil.
+func keepChangedLines(changed *changes.Set, violations []model.Violation) []model.Violation {
+	if changed == nil {
+		return violations
+	}
+
+	var kept []model.Violation
+	for _, v := range violations {
+		if changed.Changed(v.Position.File, v.Position.Line) {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// keepTouchedDecls drops package violations whose anchoring declaration was
+// not touched. Violations outside any declaration must be on a changed line.
+func keepTouchedDecls(changed *changes.Set, pkg *packages.Package, violations []model.Violation) []model.Violation {
+	if changed == nil {
+		return violations
+	}
+
+	files := make(map[string]*ast.File, len(pkg.Syntax))
+	for _, f := range pkg.Syntax {
+		files[filepath.Clean(pkg.Fset.File(f.Pos()).Name())] = f
+	}
+
+	var kept []model.Violation
+	for _, v := range violations {
+		start, end, ok := declLines(pkg, files[filepath.Clean(v.Position.File)], v.Position.Line)
+		if ok && changed.Touched(v.Position.File, start, end) || !ok && changed.Changed(v.Position.File, v.Position.Line) {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// declLines returns the lines, including the doc comment, of the declaration
+// in file enclosing line. Specs of grouped declarations count on their own.
+func declLines(pkg *packages.Package, file *ast.File, line int) (int, int, bool) {
+	if file == nil {
+		return 0, 0, false
+	}
+
+	contains := func(start, end ast.Node) bool {
+		return pkg.Fset.Position(start.Pos()).Line <= line && line <= pkg.Fset.Position(end.End()).Line
+	}
+	lines := func(start, end ast.Node) (int, int, bool) {
+		return pkg.Fset.Position(start.Pos()).Line, pkg.Fset.Position(end.End()).Line, true
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if contains(withDoc(d, d.Doc), d) {
+				return lines(withDoc(d, d.Doc), d)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				start := specStart(spec)
+				if d.Lparen == 0 {
+					start = withDoc(d, d.Doc)
+				}
+				if contains(start, spec) {
+					return lines(start, spec)
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// withDoc returns the doc comment of node when it has one.
+func withDoc(node ast.Node, doc *ast.CommentGroup) ast.Node {
+	if doc != nil {
+		return doc
+	}
+	return node
+}
+
+func specStart(spec ast.Spec) ast.Node {
+	switch s := spec.(type) {
+	case *ast.TypeSpec:
+		return withDoc(s, s.Doc)
+	case *ast.ValueSpec:
+		return withDoc(s, s.Doc)
+	default:
+		return spec
+	}
+}
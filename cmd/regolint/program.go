@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/burdzwastaken/regolint/internal/callgraph"
+	"github.com/burdzwastaken/regolint/internal/config"
+	"github.com/burdzwastaken/regolint/internal/evaluator"
+	"github.com/burdzwastaken/regolint/internal/taint"
+	"github.com/burdzwastaken/regolint/internal/transformer"
+	"golang.org/x/tools/go/packages"
+)
+
+// analyzeProgram builds the SSA form of pkgs once and derives from it the
+// call graph selected by analysis.call_graph and, when analysis.taint is set
+// and policies declare sources and sinks, the taint flows. It returns the
+// call graph for go.reachable, and the transformer options that add both to
+// the policy input.
+func analyzeProgram(ctx context.Context, pkgs []*packages.Package, eval *evaluator.Evaluator, cfg *config.Config) (*callgraph.Graph, []transformer.Option, error) {
+	opts := []transformer.Option{transformer.WithInterfaces(cfg.Analysis.Interfaces)}
+
+	algorithm := callGraphAlgorithm(cfg, eval)
+
+	var spec taint.Spec
+	if cfg.Analysis.Taint {
+		var err error
+		if spec, err = eval.TaintSpec(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+	if algorithm == "none" && spec.Empty() {
+		return nil, opts, nil
+	}
+
+	prog := callgraph.Program(pkgs)
+
+	var graph *callgraph.Graph
+	if algorithm != "none" {
+		var err error
+		graph, err = callgraph.Build(prog, algorithm)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid analysis.call_graph: %w", err)
+		}
+		opts = append(opts, transformer.WithCallGraph(graph))
+	}
+
+	if !spec.Empty() {
+		for _, name := range spec.Unresolved(prog) {
+			fmt.Fprintf(os.Stderr, "warning: taint function %s is not declared by any analyzed package or dependency\n", name)
+		}
+		opts = append(opts, transformer.WithTaintFlows(taint.Analyze(prog, spec)))
+	}
+	return graph, opts, nil
+}
+
+// callGraphAlgorithm returns the call graph algorithm selected by
+// analysis.call_graph, resolving "auto" to VTA when a policy calls
+// go.reachable and to "none" otherwise.
+func callGraphAlgorithm(cfg *config.Config, eval *evaluator.Evaluator) string {
+	if cfg.Analysis.CallGraph != "auto" {
+		return cfg.Analysis.CallGraph
+	}
+	if eval.Calls("go.reachable") {
+		return callgraph.VTA
+	}
+	return "none"
+}
@@ -0,0 +1,270 @@
+// Package baseline records existing violations so that later runs report
+// only new ones.
+package baseline
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/burdzwastaken/regolint/internal/model"
+)
+
+// version is the baseline file format version.
+const version = 1
+
+// Entry is the fingerprint of one or more identical violations. It avoids raw
+// line numbers so that unrelated edits to a file do not invalidate it.
+type Entry struct {
+	Rule string `json:"rule"`
+	// File is relative to the directory of the baseline file.
+	File string `json:"file"`
+	// Symbol is the enclosing function, method (Type.Method) or type.
+	Symbol string `json:"symbol,omitempty"`
+	// Source is the violation's line with whitespace normalized.
+	Source string `json:"source"`
+	Count  int    `json:"count"`
+}
+
+// Baseline is a set of fingerprinted violations.
+type Baseline struct {
+	Version int     `json:"version"`
+	Entries []Entry `json:"entries"`
+
+	// path is where the baseline is stored; files are relative to its directory.
+	path string
+}
+
+// Files holds the syntax trees the violations were found in, keyed by file
+// path, and the file set they were parsed with. Files missing from it are
+// parsed from disk.
+// nolint:TAG001 // not serialized
+type Files struct {
+	Fset   *token.FileSet
+	Syntax map[string]*ast.File
+}
+
+// New fingerprints violations into a baseline to be stored at path.
+func New(path string, violations []model.Violation, files Files) *Baseline {
+	fp := newFingerprinter(filepath.Dir(path), files)
+
+	counts := make(map[Entry]int)
+	for _, v := range violations {
+		counts[fp.fingerprint(v)]++
+	}
+
+	b := &Baseline{Version: version, Entries: make([]Entry, 0, len(counts)), path: path}
+	for key, n := range counts {
+		key.Count = n
+		b.Entries = append(b.Entries, key)
+	}
+	slices.SortFunc(b.Entries, func(a, b Entry) int {
+		return cmp.Or(
+			cmp.Compare(a.File, b.File),
+			cmp.Compare(a.Symbol, b.Symbol),
+			cmp.Compare(a.Rule, b.Rule),
+			cmp.Compare(a.Source, b.Source),
+		)
+	})
+
+	return b
+}
+
+// Load reads a baseline file.
+func Load(path string) (*Baseline, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("reading baseline: %w", err)
+	}
+
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parsing baseline %s: %w", path, err)
+	}
+	if b.Version != version {
+		return nil, fmt.Errorf("baseline %s has unsupported version %d", path, b.Version)
+	}
+	b.path = path
+
+	return &b, nil
+}
+
+// Write saves the baseline.
+func (b *Baseline) Write() error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(b.path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("writing baseline: %w", err)
+	}
+	return nil
+}
+
+// Filter drops the violations recorded in the baseline. Each entry suppresses
+// at most Count violations. It also returns the entries, with their unused
+// counts, that matched fewer violations than recorded, so the baseline can be
+// shrunk.
+func (b *Baseline) Filter(violations []model.Violation, files Files) ([]model.Violation, []Entry) {
+	fp := newFingerprinter(filepath.Dir(b.path), files)
+
+	remaining := make(map[Entry]int, len(b.Entries))
+	for _, e := range b.Entries {
+		count := e.Count
+		e.Count = 0
+		remaining[e] += count
+	}
+
+	var kept []model.Violation
+	for _, v := range violations {
+		key := fp.fingerprint(v)
+		if remaining[key] > 0 {
+			remaining[key]--
+			continue
+		}
+		kept = append(kept, v)
+	}
+
+	var stale []Entry
+	for _, e := range b.Entries {
+		key := e
+		key.Count = 0
+		if n := remaining[key]; n > 0 {
+			key.Count = n
+			stale = append(stale, key)
+			remaining[key] = 0
+		}
+	}
+
+	return kept, stale
+}
+
+// fingerprinter computes entries for violations, reading each file once and
+// parsing only the files that were not loaded.
+type fingerprinter struct {
+	dir    string
+	fset   *token.FileSet
+	loaded Files
+	files  map[string]*sourceFile
+}
+
+type sourceFile struct {
+	lines [][]byte
+	fset  *token.FileSet
+	ast   *ast.File
+}
+
+func newFingerprinter(dir string, loaded Files) *fingerprinter {
+	return &fingerprinter{dir: dir, fset: token.NewFileSet(), loaded: loaded, files: make(map[string]*sourceFile)}
+}
+
+// fingerprint computes the entry for v. Violations in files that cannot be
+// read are fingerprinted by rule and file alone.
+func (f *fingerprinter) fingerprint(v model.Violation) Entry {
+	entry := Entry{Rule: v.Rule, File: filepath.ToSlash(v.Position.File)}
+
+	if abs, err := filepath.Abs(v.Position.File); err == nil {
+		if dir, err := filepath.Abs(f.dir); err == nil {
+			if rel, err := filepath.Rel(dir, abs); err == nil {
+				entry.File = filepath.ToSlash(rel)
+			}
+		}
+	}
+
+	src := f.load(v.Position.File)
+	if v.Position.Line > 0 && v.Position.Line <= len(src.lines) {
+		entry.Source = string(bytes.Join(bytes.Fields(src.lines[v.Position.Line-1]), []byte(" ")))
+	}
+	if src.ast != nil {
+		entry.Symbol = enclosingSymbol(src.fset, src.ast, v.Position.Line)
+	}
+
+	return entry
+}
+
+func (f *fingerprinter) load(path string) *sourceFile {
+	if src, ok := f.files[path]; ok {
+		return src
+	}
+
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		src := &sourceFile{}
+		f.files[path] = src
+		return src
+	}
+
+	src := &sourceFile{lines: bytes.Split(data, []byte("\n"))}
+	if file, ok := f.loaded.Syntax[path]; ok {
+		src.fset, src.ast = f.loaded.Fset, file
+	} else {
+		// A file that does not parse still has lines to fingerprint.
+		src.fset = f.fset
+		src.ast, _ = parser.ParseFile(f.fset, path, data, parser.SkipObjectResolution)
+	}
+	f.files[path] = src
+	return src
+}
+
+// enclosingSymbol names the top-level declaration containing line.
+func enclosingSymbol(fset *token.FileSet, file *ast.File, line int) string {
+	for _, decl := range file.Decls {
+		if fset.Position(decl.Pos()).Line > line || fset.Position(decl.End()).Line < line {
+			continue
+		}
+
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				return receiverName(d.Recv.List[0].Type) + "." + d.Name.Name
+			}
+			return d.Name.Name
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				if fset.Position(spec.Pos()).Line <= line && fset.Position(spec.End()).Line >= line {
+					return specSymbol(spec)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// specSymbol names a type, or the variables or constants, declared by spec.
+func specSymbol(spec ast.Spec) string {
+	switch s := spec.(type) {
+	case *ast.TypeSpec:
+		return s.Name.Name
+	case *ast.ValueSpec:
+		names := make([]string, 0, len(s.Names))
+		for _, name := range s.Names {
+			names = append(names, name.Name)
+		}
+		return strings.Join(names, ",")
+	default:
+		return ""
+	}
+}
+
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	default:
+		return ""
+	}
+}
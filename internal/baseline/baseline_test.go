@@ -0,0 +1,149 @@
+package baseline
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/burdzwastaken/regolint/internal/model"
+)
+
+func writeFile(t *testing.T, path, src string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func violation(rule, file string, line int) model.Violation {
+	return model.Violation{Rule: rule, Position: model.Position{File: file, Line: line, Column: 1}}
+}
+
+func TestNewFingerprints(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "pkg", "a.go")
+	writeFile(t, file, "package pkg\n\ntype T struct{}\n\nfunc (t *T) M() {\n\tpanic(  \"x\")\n\tpanic(\"x\")\n}\n\nvar A, B = 1, 2\n")
+
+	b := New(filepath.Join(dir, "baseline.json"), []model.Violation{
+		violation("ERR001", file, 6),
+		violation("ERR001", file, 7),
+		violation("NAM001", file, 3),
+		violation("VAR001", file, 10),
+	}, Files{})
+
+	want := []Entry{
+		{Rule: "VAR001", File: "pkg/a.go", Symbol: "A,B", Source: "var A, B = 1, 2", Count: 1},
+		{Rule: "NAM001", File: "pkg/a.go", Symbol: "T", Source: "type T struct{}", Count: 1},
+		{Rule: "ERR001", File: "pkg/a.go", Symbol: "T.M", Source: "panic( \"x\")", Count: 1},
+		{Rule: "ERR001", File: "pkg/a.go", Symbol: "T.M", Source: "panic(\"x\")", Count: 1},
+	}
+	if len(b.Entries) != len(want) {
+		t.Fatalf("Entries = %+v, want %+v", b.Entries, want)
+	}
+	for i := range want {
+		if b.Entries[i] != want[i] {
+			t.Errorf("Entries[%d] = %+v, want %+v", i, b.Entries[i], want[i])
+		}
+	}
+}
+
+func TestFilterSurvivesLineShifts(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.go")
+	path := filepath.Join(dir, "baseline.json")
+
+	writeFile(t, file, "package a\n\nfunc f() {\n\tpanic(1)\n\tpanic(1)\n}\n")
+	if err := New(path, []model.Violation{
+		violation("ERR001", file, 4),
+		violation("ERR001", file, 5),
+	}, Files{}).Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lines were inserted above and a third identical panic was added.
+	writeFile(t, file, "package a\n\nimport \"os\"\n\nfunc f() {\n\t_ = os.Args\n\tpanic(1)\n\tpanic(1)\n\tpanic(1)\n}\n")
+	kept, stale := b.Filter([]model.Violation{
+		violation("ERR001", file, 7),
+		violation("ERR001", file, 8),
+		violation("ERR001", file, 9),
+	}, Files{})
+
+	if len(kept) != 1 || kept[0].Position.Line != 9 {
+		t.Errorf("kept = %+v, want the violation on line 9", kept)
+	}
+	if len(stale) != 0 {
+		t.Errorf("stale = %+v, want none", stale)
+	}
+}
+
+func TestFilterReportsStaleEntries(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.go")
+	writeFile(t, file, "package a\n\nfunc f() {\n\tpanic(1)\n\tpanic(1)\n}\n\nfunc g() {}\n")
+
+	b := New(filepath.Join(dir, "baseline.json"), []model.Violation{
+		violation("ERR001", file, 4),
+		violation("ERR001", file, 5),
+		violation("DOC001", file, 8),
+	}, Files{})
+
+	kept, stale := b.Filter([]model.Violation{violation("ERR001", file, 4)}, Files{})
+	if len(kept) != 0 {
+		t.Errorf("kept = %+v, want none", kept)
+	}
+
+	want := []Entry{
+		{Rule: "ERR001", File: "a.go", Symbol: "f", Source: "panic(1)", Count: 1},
+		{Rule: "DOC001", File: "a.go", Symbol: "g", Source: "func g() {}", Count: 1},
+	}
+	if len(stale) != len(want) {
+		t.Fatalf("stale = %+v, want %+v", stale, want)
+	}
+	for i := range want {
+		if stale[i] != want[i] {
+			t.Errorf("stale[%d] = %+v, want %+v", i, stale[i], want[i])
+		}
+	}
+}
+
+func TestNewUsesLoadedFiles(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.go")
+	writeFile(t, file, "package a\n\nfunc onDisk() {\n\tpanic(1)\n}\n")
+
+	// The loaded syntax tree names the function differently, so the symbol
+	// shows which one was used.
+	fset := token.NewFileSet()
+	loaded, err := parser.ParseFile(fset, file, "package a\n\nfunc loaded() {\n\tpanic(1)\n}\n", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := New(filepath.Join(dir, "baseline.json"), []model.Violation{violation("ERR001", file, 4)},
+		Files{Fset: fset, Syntax: map[string]*ast.File{file: loaded}})
+
+	want := Entry{Rule: "ERR001", File: "a.go", Symbol: "loaded", Source: "panic(1)", Count: 1}
+	if len(b.Entries) != 1 || b.Entries[0] != want {
+		t.Errorf("Entries = %+v, want [%+v]", b.Entries, want)
+	}
+}
+
+func TestLoadRejectsUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	writeFile(t, path, `{"version": 99, "entries": []}`)
+
+	if _, err := Load(path); err == nil {
+		t.Fatal("Load() error = nil, want unsupported version")
+	}
+}
@@ -0,0 +1,206 @@
+// Package callgraph builds a static call graph of the analyzed packages so
+// that policies can reason about transitive calls.
+package callgraph
+
+import (
+	"context"
+	"fmt"
+	"go/types"
+	"slices"
+
+	"golang.org/x/tools/go/callgraph/cha"
+	"golang.org/x/tools/go/callgraph/vta"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// Algorithms that Build accepts. CHA resolves a dynamic call to every
+// method of a matching name and signature; VTA refines the CHA graph by
+// tracking which types flow to each call site.
+const (
+	CHA = "cha"
+	VTA = "vta"
+)
+
+// Graph is a call graph whose nodes are functions named as by Name. Function
+// literals are merged into the function declaring them, and generic
+// functions stand for all their instances. Calls into packages loaded
+// without syntax are edges to functions with no callees of their own.
+type Graph struct {
+	callees  map[string][]string
+	callers  map[string][]string
+	packages map[string]string
+}
+
+// Program builds the SSA form of pkgs and of the functions they use from
+// their dependencies. Packages that failed to type-check are left out.
+func Program(pkgs []*packages.Package) *ssa.Program {
+	prog, _ := ssautil.Packages(pkgs, ssa.InstantiateGenerics)
+	prog.Build()
+	return prog
+}
+
+// Build constructs the call graph of prog with the given algorithm.
+func Build(prog *ssa.Program, algorithm string) (*Graph, error) {
+	if algorithm != CHA && algorithm != VTA {
+		return nil, fmt.Errorf("unknown call graph algorithm %q", algorithm)
+	}
+
+	cg := cha.CallGraph(prog)
+	if algorithm == VTA {
+		cg = vta.CallGraph(ssautil.AllFunctions(prog), cg)
+	}
+
+	g := &Graph{
+		callees:  make(map[string][]string),
+		callers:  make(map[string][]string),
+		packages: make(map[string]string),
+	}
+	for fn, node := range cg.Nodes {
+		if fn == nil {
+			continue
+		}
+		from := g.add(fn)
+		for _, edge := range node.Out {
+			to := g.add(edge.Callee.Func)
+			if to != from {
+				g.callees[from] = append(g.callees[from], to)
+				g.callers[to] = append(g.callers[to], from)
+			}
+		}
+	}
+
+	for _, edges := range []map[string][]string{g.callees, g.callers} {
+		for name, names := range edges {
+			slices.Sort(names)
+			edges[name] = slices.Compact(names)
+		}
+	}
+	return g, nil
+}
+
+// add records the node standing for fn and returns its name.
+func (g *Graph) add(fn *ssa.Function) string {
+	name, pkg := node(fn)
+	g.packages[name] = pkg
+	return name
+}
+
+// NameOf returns the name of the graph node standing for fn, which is that of
+// the declared function for function literals and generic instances.
+func NameOf(fn *ssa.Function) string {
+	name, _ := node(fn)
+	return name
+}
+
+// node returns the name of the node standing for fn and its package path.
+func node(fn *ssa.Function) (name, pkg string) {
+	for fn.Parent() != nil {
+		fn = fn.Parent()
+	}
+	if origin := fn.Origin(); origin != nil {
+		fn = origin
+	}
+
+	if obj, ok := fn.Object().(*types.Func); ok {
+		name = Name(obj)
+		if obj.Pkg() != nil {
+			pkg = obj.Pkg().Path()
+		}
+	} else if fn.Pkg != nil {
+		// Package initializers and other synthetic functions.
+		pkg = fn.Pkg.Pkg.Path()
+		name = pkg + "." + fn.Name()
+	} else {
+		name = fn.String()
+	}
+	return name, pkg
+}
+
+// Callees returns the functions fn calls directly, sorted by name.
+func (g *Graph) Callees(fn string) []string {
+	return g.callees[fn]
+}
+
+// Callers returns the functions calling fn directly, sorted by name.
+func (g *Graph) Callers(fn string) []string {
+	return g.callers[fn]
+}
+
+// Reachable reports whether a function matching to can be reached from a
+// function matching from by a chain of one or more calls. Each may be a
+// function name or a package path, which matches every function of that
+// package.
+func (g *Graph) Reachable(from, to string) bool {
+	matches := func(name, pattern string) bool {
+		return name == pattern || g.packages[name] == pattern
+	}
+
+	var queue []string
+	visited := make(map[string]bool)
+	for name := range g.packages {
+		if matches(name, from) {
+			queue = append(queue, name)
+		}
+	}
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, callee := range g.callees[name] {
+			if matches(callee, to) {
+				return true
+			}
+			if !visited[callee] {
+				visited[callee] = true
+				queue = append(queue, callee)
+			}
+		}
+	}
+	return false
+}
+
+// Name renders fn as pkg/path.Func, pkg/path.Type.Method or
+// pkg/path.(*Type).Method, the form used for callees throughout the input.
+func Name(fn *types.Func) string {
+	fn = fn.Origin()
+	recv := fn.Signature().Recv()
+	if recv == nil {
+		if fn.Pkg() == nil {
+			return fn.Name()
+		}
+		return fn.Pkg().Path() + "." + fn.Name()
+	}
+
+	typ, ptr := recv.Type(), false
+	if p, ok := typ.(*types.Pointer); ok {
+		typ, ptr = p.Elem(), true
+	}
+	named, ok := types.Unalias(typ).(*types.Named)
+	if !ok {
+		return fn.FullName()
+	}
+
+	name := named.Obj().Name()
+	if ptr {
+		name = "(*" + name + ")"
+	}
+	if pkg := named.Obj().Pkg(); pkg != nil {
+		name = pkg.Path() + "." + name
+	}
+	return name + "." + fn.Name()
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying g, for the go.reachable built-in.
+func NewContext(ctx context.Context, g *Graph) context.Context {
+	return context.WithValue(ctx, contextKey{}, g)
+}
+
+// FromContext returns the graph carried by ctx, or nil.
+func FromContext(ctx context.Context) *Graph {
+	g, _ := ctx.Value(contextKey{}).(*Graph)
+	return g
+}
@@ -0,0 +1,139 @@
+package callgraph_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/burdzwastaken/regolint/internal/callgraph"
+	"github.com/burdzwastaken/regolint/internal/testenv"
+	"golang.org/x/tools/go/packages"
+)
+
+var testModule = map[string]string{
+	"go.mod": "module example.com/m\n\ngo 1.22\n",
+	"handler/handler.go": `package handler
+
+import (
+	"net/http"
+	"os/exec"
+
+	"example.com/m/store"
+)
+
+type Runner interface{ Run() error }
+
+type shell struct{}
+
+func (shell) Run() error { return exec.Command("true").Run() }
+
+func Serve(w http.ResponseWriter, r *http.Request) { helper() }
+
+func helper() {
+	func() { store.Save() }()
+}
+
+func Admin(r Runner) error { return r.Run() }
+
+func Use() { _ = Admin(shell{}) }
+`,
+	"store/store.go": `package store
+
+import "os"
+
+func Map[T any](x T) T { return x }
+
+func Save() { _ = Map(os.Getenv("X")) }
+`,
+}
+
+func loadModule(t *testing.T) []*packages.Package {
+	t.Helper()
+	testenv.MustReadExportData(t)
+
+	dir := t.TempDir()
+	for name, src := range testModule {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedSyntax |
+			packages.NeedTypes | packages.NeedTypesInfo,
+		Dir: dir,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		t.Fatalf("loading packages: %v", err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Fatal("packages have errors")
+	}
+	return pkgs
+}
+
+func TestBuild(t *testing.T) {
+	prog := callgraph.Program(loadModule(t))
+
+	for _, algorithm := range []string{callgraph.CHA, callgraph.VTA} {
+		t.Run(algorithm, func(t *testing.T) {
+			g, err := callgraph.Build(prog, algorithm)
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+
+			if got := g.Callees("example.com/m/handler.helper"); !slices.Equal(got, []string{"example.com/m/store.Save"}) {
+				t.Errorf("Callees(helper) = %v, want calls from closures merged into helper", got)
+			}
+			if got := g.Callers("example.com/m/store.Save"); !slices.Equal(got, []string{"example.com/m/handler.helper"}) {
+				t.Errorf("Callers(Save) = %v", got)
+			}
+			if got := g.Callees("example.com/m/store.Save"); !slices.Equal(got, []string{"example.com/m/store.Map", "os.Getenv"}) {
+				t.Errorf("Callees(Save) = %v, want generic instances merged into Map", got)
+			}
+
+			tests := []struct {
+				from, to string
+				want     bool
+			}{
+				{"example.com/m/handler.Serve", "os.Getenv", true},
+				{"example.com/m/handler.Serve", "os", true},
+				{"example.com/m/handler.Serve", "os/exec", false},
+				{"example.com/m/handler.Admin", "os/exec", true},
+				{"example.com/m/handler", "os/exec.(*Cmd).Run", true},
+				{"example.com/m/store", "example.com/m/handler", false},
+				{"example.com/m/handler.helper", "example.com/m/handler.helper", false},
+				{"example.com/m/missing", "os", false},
+			}
+			for _, tt := range tests {
+				if got := g.Reachable(tt.from, tt.to); got != tt.want {
+					t.Errorf("Reachable(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildUnknownAlgorithm(t *testing.T) {
+	if _, err := callgraph.Build(nil, "rta"); err == nil {
+		t.Error("Build() error = nil, want error for unknown algorithm")
+	}
+}
+
+func TestContext(t *testing.T) {
+	if g := callgraph.FromContext(context.Background()); g != nil {
+		t.Errorf("FromContext() = %v, want nil", g)
+	}
+
+	g := &callgraph.Graph{}
+	if got := callgraph.FromContext(callgraph.NewContext(context.Background(), g)); got != g {
+		t.Error("FromContext() did not return the graph from NewContext")
+	}
+}
@@ -0,0 +1,265 @@
+// Package changes computes the lines changed relative to a git revision or
+// in a patch, so that only violations in new code are reported.
+package changes
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Range is an inclusive range of changed lines. A range whose Start is one
+// past its End marks lines deleted between End and Start.
+// nolint:TAG001 // not serialized
+type Range struct {
+	Start int
+	End   int
+}
+
+// Set holds the changed ranges of each file, keyed by absolute path.
+type Set struct {
+	files map[string][]Range
+}
+
+// Changed reports whether line of file was added or modified.
+func (s *Set) Changed(file string, line int) bool {
+	for _, r := range s.files[filepath.Clean(file)] {
+		if r.Start <= line && line <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// Touched reports whether any line in [start, end] of file was added or
+// modified, or lines were deleted between two lines of it.
+func (s *Set) Touched(file string, start, end int) bool {
+	for _, r := range s.files[filepath.Clean(file)] {
+		if r.Start <= end && r.End >= start {
+			return true
+		}
+	}
+	return false
+}
+
+// FromRev returns the changes in the working tree relative to rev, including
+// untracked files, which are entirely new.
+func FromRev(ctx context.Context, rev string) (*Set, error) {
+	root, err := git(ctx, "", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	dir := strings.TrimSpace(string(root))
+
+	diff, err := git(ctx, dir, "diff", "--no-color", "--no-ext-diff", "-U0", "--end-of-options", rev, "--")
+	if err != nil {
+		return nil, err
+	}
+	s, err := Parse(bytes.NewReader(diff), dir)
+	if err != nil {
+		return nil, err
+	}
+
+	untracked, err := git(ctx, dir, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	for name := range strings.SplitSeq(string(untracked), "\x00") {
+		if name != "" {
+			s.add(filepath.Join(dir, name), Range{Start: 1, End: math.MaxInt})
+		}
+	}
+
+	return s, nil
+}
+
+// FromPatch returns the changes in a unified diff file. Paths in the patch are
+// relative to the root of the enclosing git repository, or to the working
+// directory outside one.
+func FromPatch(ctx context.Context, path string) (*Set, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("reading patch: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	root := "."
+	if out, err := git(ctx, "", "rev-parse", "--show-toplevel"); err == nil {
+		root = strings.TrimSpace(string(out))
+	}
+
+	return Parse(f, root)
+}
+
+// Parse reads a unified diff whose paths are relative to root.
+func Parse(r io.Reader, root string) (*Set, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{set: &Set{files: make(map[string][]Range)}, root: root}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	for scanner.Scan() {
+		if err := p.next(scanner.Text()); err != nil {
+			return nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading diff: %w", err)
+	}
+	return p.set, nil
+}
+
+func (s *Set) add(file string, r Range) {
+	file = filepath.Clean(file)
+	ranges := s.files[file]
+	if n := len(ranges); n > 0 {
+		last := &ranges[n-1]
+		switch {
+		case *last == r:
+			return
+		case r.Start == r.End && last.Start <= last.End && last.End+1 == r.Start:
+			// Merge consecutive added lines into one range.
+			last.End = r.End
+			return
+		}
+	}
+	s.files[file] = append(ranges, r)
+}
+
+// parser tracks the position within a unified diff.
+type parser struct {
+	set  *Set
+	root string
+	// file is the new name of the file being read, or empty when its hunks
+	// are skipped because it was deleted.
+	file string
+	// line is the new-side line number of the next hunk line.
+	line int
+	// oldLeft and newLeft count the lines remaining in the current hunk.
+	oldLeft, newLeft int
+}
+
+// next consumes one line of the diff.
+func (p *parser) next(text string) error {
+	if p.oldLeft > 0 || p.newLeft > 0 {
+		p.hunkLine(text)
+		return nil
+	}
+
+	switch {
+	case strings.HasPrefix(text, "+++ "):
+		name, err := diffPath(strings.TrimPrefix(text, "+++ "))
+		if err != nil {
+			return err
+		}
+		p.file = ""
+		if name != "" {
+			p.file = filepath.Join(p.root, name)
+		}
+	case strings.HasPrefix(text, "@@ "):
+		return p.hunkHeader(text)
+	}
+	return nil
+}
+
+func (p *parser) hunkLine(text string) {
+	switch {
+	case strings.HasPrefix(text, "+"):
+		if p.file != "" {
+			p.set.add(p.file, Range{Start: p.line, End: p.line})
+		}
+		p.line++
+		p.newLeft--
+	case strings.HasPrefix(text, "-"):
+		if p.file != "" {
+			p.set.add(p.file, Range{Start: p.line, End: p.line - 1})
+		}
+		p.oldLeft--
+	case strings.HasPrefix(text, "\\"):
+		// "\ No newline at end of file"
+	default:
+		p.line++
+		p.oldLeft--
+		p.newLeft--
+	}
+}
+
+// hunkHeader parses "@@ -l[,s] +l[,s] @@".
+func (p *parser) hunkHeader(text string) error {
+	fields := strings.Fields(text)
+	if len(fields) < 4 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return fmt.Errorf("malformed hunk header %q", text)
+	}
+
+	_, oldCount, err := hunkRange(fields[1][1:])
+	if err != nil {
+		return fmt.Errorf("malformed hunk header %q: %w", text, err)
+	}
+	newStart, newCount, err := hunkRange(fields[2][1:])
+	if err != nil {
+		return fmt.Errorf("malformed hunk header %q: %w", text, err)
+	}
+
+	p.line, p.oldLeft, p.newLeft = newStart, oldCount, newCount
+	// An empty new side starts after the given line.
+	if newCount == 0 {
+		p.line++
+	}
+	return nil
+}
+
+func hunkRange(s string) (int, int, error) {
+	start, count, ok := strings.Cut(s, ",")
+	first, err := strconv.Atoi(start)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !ok {
+		return first, 1, nil
+	}
+	n, err := strconv.Atoi(count)
+	return first, n, err
+}
+
+// diffPath extracts the path from a "+++" header, dropping the "b/" prefix
+// and any timestamp. It returns an empty path for /dev/null.
+func diffPath(s string) (string, error) {
+	if strings.HasPrefix(s, `"`) {
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return "", fmt.Errorf("malformed path %s: %w", s, err)
+		}
+		s = unquoted
+	} else if name, _, ok := strings.Cut(s, "\t"); ok {
+		s = name
+	}
+
+	if s == "/dev/null" {
+		return "", nil
+	}
+	return strings.TrimPrefix(s, "b/"), nil
+}
+
+func git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,140 @@
+package changes
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const patch = `diff --git a/a.go b/a.go
+index 1111111..2222222 100644
+--- a/a.go
++++ b/a.go
+@@ -3,4 +3,5 @@ package a
+ func f() {
+-	old()
++	new()
++	more()
+ }
+
+@@ -20,2 +21,0 @@ func g() {
+-	gone()
+-	gone()
+diff --git a/deleted.go b/deleted.go
+deleted file mode 100644
+--- a/deleted.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package a
+diff --git a/new.go b/new.go
+new file mode 100644
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1,2 @@
++package a
++
+`
+
+func TestParse(t *testing.T) {
+	s, err := Parse(strings.NewReader(patch), "/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed := []struct {
+		file string
+		line int
+		want bool
+	}{
+		{"/repo/a.go", 3, false},
+		{"/repo/a.go", 4, true},
+		{"/repo/a.go", 5, true},
+		{"/repo/a.go", 6, false},
+		{"/repo/a.go", 21, false},
+		{"/repo/new.go", 1, true},
+		{"/repo/new.go", 2, true},
+		{"/repo/deleted.go", 1, false},
+	}
+	for _, tt := range changed {
+		if got := s.Changed(tt.file, tt.line); got != tt.want {
+			t.Errorf("Changed(%s, %d) = %v, want %v", tt.file, tt.line, got, tt.want)
+		}
+	}
+
+	touched := []struct {
+		start, end int
+		want       bool
+	}{
+		{1, 3, false},
+		{3, 6, true},
+		// Lines were deleted between 21 and 22.
+		{19, 22, true},
+		{22, 25, false},
+		{10, 21, false},
+	}
+	for _, tt := range touched {
+		if got := s.Touched("/repo/a.go", tt.start, tt.end); got != tt.want {
+			t.Errorf("Touched(a.go, %d, %d) = %v, want %v", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestParseMalformedHunk(t *testing.T) {
+	if _, err := Parse(strings.NewReader("+++ b/a.go\n@@ -x +1 @@\n"), "/repo"); err == nil {
+		t.Fatal("Parse() error = nil, want malformed hunk header")
+	}
+}
+
+func TestFromRev(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, src string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q")
+	write("a.go", "package a\n\nfunc f() {}\n")
+	run("add", "a.go")
+	run("commit", "-q", "-m", "initial")
+
+	write("a.go", "package a\n\nfunc f() {}\n\nfunc g() {}\n")
+	write("b.go", "package a\n")
+
+	t.Chdir(dir)
+	s, err := FromRev(context.Background(), "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Changed(filepath.Join(root, "a.go"), 3) {
+		t.Error("unchanged line 3 of a.go reported as changed")
+	}
+	if !s.Changed(filepath.Join(root, "a.go"), 5) {
+		t.Error("added line 5 of a.go not reported as changed")
+	}
+	if !s.Changed(filepath.Join(root, "b.go"), 1) {
+		t.Error("untracked b.go not reported as changed")
+	}
+}
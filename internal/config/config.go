@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/bmatcuk/doublestar/v4"
 	"gopkg.in/yaml.v3"
@@ -21,6 +22,14 @@ type Config struct {
 	Exclude     []string          `yaml:"exclude"`
 	Output      OutputConfig      `yaml:"output"`
 	Performance PerformanceConfig `yaml:"performance"`
+	Analysis    AnalysisConfig    `yaml:"analysis"`
+
+	// ExcludeGenerated skips files marked as generated by a
+	// "// Code generated ... DO NOT EDIT." comment.
+	ExcludeGenerated bool `yaml:"exclude_generated"`
+
+	// Path is the file the configuration was loaded from, if any.
+	Path string `yaml:"-"`
 }
 
 // PoliciesConfig specifies where to load policies from.
@@ -58,6 +67,46 @@ type PerformanceConfig struct {
 	CachePolicies bool   `yaml:"cache_policies"`
 	Parallelism   int    `yaml:"parallelism"`
 	Timeout       string `yaml:"timeout"`
+	Budget        string `yaml:"budget"`
+}
+
+// AnalysisConfig tunes the information extracted for policies.
+// nolint:TAG001 // uses yaml tags
+type AnalysisConfig struct {
+	// Interfaces are well-known interfaces, such as io.Reader or
+	// encoding/json.Marshaler, that every type is checked against in addition
+	// to the interfaces of its own package and direct imports.
+	Interfaces []string `yaml:"interfaces"`
+
+	// CallGraph selects how the static call graph behind function callees
+	// and go.reachable is built: "auto" (the default) builds it with "vta"
+	// only when a policy calls go.reachable, "cha" is cheap but imprecise,
+	// "vta" is the most precise, and "none" skips it.
+	CallGraph string `yaml:"call_graph"`
+
+	// Taint enables tracking values from the taint sources to the sinks
+	// declared by policies, reported as taint_flows.
+	Taint bool `yaml:"taint"`
+}
+
+// DefaultInterfaces is the default list of well-known interfaces.
+var DefaultInterfaces = []string{
+	"error",
+	"fmt.Stringer",
+	"fmt.Formatter",
+	"io.Reader",
+	"io.Writer",
+	"io.Closer",
+	"io.ReaderFrom",
+	"io.WriterTo",
+	"sort.Interface",
+	"encoding.TextMarshaler",
+	"encoding.TextUnmarshaler",
+	"encoding.BinaryMarshaler",
+	"encoding.BinaryUnmarshaler",
+	"encoding/json.Marshaler",
+	"encoding/json.Unmarshaler",
+	"net/http.Handler",
 }
 
 // Default returns a Config with sensible defaults.
@@ -80,6 +129,10 @@ func Default() *Config {
 			Parallelism:   4,
 			Timeout:       "30s",
 		},
+		Analysis: AnalysisConfig{
+			Interfaces: slices.Clone(DefaultInterfaces),
+			CallGraph:  "auto",
+		},
 	}
 }
 
@@ -180,9 +233,40 @@ func (c *Config) GetSeverity(ruleID, defaultSeverity string) string {
 	return defaultSeverity
 }
 
-// ShouldSkip returns true if the file should be excluded from linting.
+// GetTimeout returns the per-file and per-package evaluation timeout, or zero
+// if none is configured.
+func (c *Config) GetTimeout() (time.Duration, error) {
+	return parseDuration("performance.timeout", c.Performance.Timeout)
+}
+
+// GetBudget returns the wall-clock budget for a whole run, or zero if none is
+// configured.
+func (c *Config) GetBudget() (time.Duration, error) {
+	return parseDuration("performance.budget", c.Performance.Budget)
+}
+
+func parseDuration(key, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", key, value, err)
+	}
+	return d, nil
+}
+
+// ShouldSkip returns true if the file should be excluded from linting, either
+// because it matches an exclude pattern or because it matches no include pattern.
 func (c *Config) ShouldSkip(filePath string) bool {
-	for _, pattern := range c.Exclude {
+	if len(c.Include) > 0 && !matchAny(c.Include, filePath) {
+		return true
+	}
+	return matchAny(c.Exclude, filePath)
+}
+
+func matchAny(patterns []string, filePath string) bool {
+	for _, pattern := range patterns {
 		matched, err := doublestar.Match(pattern, filePath)
 		if err == nil && matched {
 			return true
@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 )
 
 func TestShouldSkip(t *testing.T) {
@@ -85,6 +86,28 @@ func TestShouldSkip(t *testing.T) {
 	}
 }
 
+func TestShouldSkipInclude(t *testing.T) {
+	cfg := &Config{
+		Include: []string{"**/*.go"},
+		Exclude: []string{"**/*_test.go"},
+	}
+
+	tests := []struct {
+		filePath string
+		want     bool
+	}{
+		{"/src/pkg/file.go", false},
+		{"/src/pkg/file_test.go", true},
+		{"/src/pkg/file.pb", true},
+	}
+
+	for _, tt := range tests {
+		if got := cfg.ShouldSkip(tt.filePath); got != tt.want {
+			t.Errorf("ShouldSkip(%q) = %v, want %v", tt.filePath, got, tt.want)
+		}
+	}
+}
+
 func TestIsRuleDisabled(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -123,3 +146,52 @@ func TestIsRuleDisabled(t *testing.T) {
 		})
 	}
 }
+
+func TestGetSeverity(t *testing.T) {
+	cfg := &Config{}
+	cfg.Rules.Severity = map[string]string{"PKG002": "info"}
+
+	if got := cfg.GetSeverity("PKG002", "warning"); got != "info" {
+		t.Errorf("GetSeverity(PKG002) = %q, want %q", got, "info")
+	}
+	if got := cfg.GetSeverity("TAG001", "warning"); got != "warning" {
+		t.Errorf("GetSeverity(TAG001) = %q, want %q", got, "warning")
+	}
+}
+
+func TestGetTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"unset", "", 0, false},
+		{"seconds", "30s", 30 * time.Second, false},
+		{"invalid", "soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			cfg.Performance.Timeout = tt.timeout
+			cfg.Performance.Budget = tt.timeout
+
+			got, err := cfg.GetTimeout()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetTimeout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetTimeout() = %v, want %v", got, tt.want)
+			}
+
+			got, err = cfg.GetBudget()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetBudget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetBudget() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
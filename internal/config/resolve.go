@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileNames lists the config file names Discover looks for, in order of preference.
+var FileNames = []string{".regolint.yml", ".regolint.yaml"}
+
+// DefaultPolicyDir is where policies are read from when no config file is
+// found.
+const DefaultPolicyDir = "./policies"
+
+// Overrides holds settings that take precedence over the config file, such as
+// CLI flags or golangci-lint plugin settings. Zero values leave the
+// configured value untouched.
+// nolint:TAG001 // not serialized
+type Overrides struct {
+	PolicyDir   string
+	PolicyFiles []string
+	Disabled    []string
+	Exclude     []string
+	Format      string
+	Parallelism int
+	Timeout     string
+	Budget      string
+}
+
+// Resolve loads the config file at path, or the one found by Discover from the
+// working directory when path is empty, and applies overrides on top of it.
+// Without a config file, policies are read from DefaultPolicyDir and no files
+// are excluded, for both the CLI and the golangci-lint plugin.
+func Resolve(path string, overrides Overrides) (*Config, error) {
+	if path == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("getting working directory: %w", err)
+		}
+		path, err = Discover(wd)
+		if err != nil {
+			return nil, err
+		}
+	} else if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+
+	cfg := Default()
+	if path != "" {
+		loaded, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		cfg = loaded
+		cfg.Path = path
+		cfg.resolvePaths(filepath.Dir(path))
+	} else {
+		cfg.Policies.Directory = DefaultPolicyDir
+		cfg.Exclude = nil
+	}
+
+	cfg.apply(overrides)
+
+	return cfg, nil
+}
+
+// Discover walks up from dir looking for a config file, stopping at the module
+// root (the first directory containing go.mod). It returns an empty path if
+// no config file is found.
+func Discover(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", dir, err)
+	}
+
+	for {
+		for _, name := range FileNames {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			}
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return "", nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// resolvePaths makes relative policy locations relative to the config file
+// rather than the working directory.
+func (c *Config) resolvePaths(base string) {
+	if c.Policies.Directory != "" && !filepath.IsAbs(c.Policies.Directory) {
+		c.Policies.Directory = filepath.Join(base, c.Policies.Directory)
+	}
+
+	for i, file := range c.Policies.Files {
+		if !filepath.IsAbs(file) {
+			c.Policies.Files[i] = filepath.Join(base, file)
+		}
+	}
+}
+
+func (c *Config) apply(o Overrides) {
+	if o.PolicyDir != "" {
+		c.Policies.Directory = o.PolicyDir
+	}
+	if len(o.PolicyFiles) > 0 {
+		c.Policies.Files = o.PolicyFiles
+	}
+	if len(o.Disabled) > 0 {
+		c.Rules.Disabled = o.Disabled
+	}
+	if len(o.Exclude) > 0 {
+		c.Exclude = o.Exclude
+	}
+	if o.Format != "" {
+		c.Output.Format = o.Format
+	}
+	if o.Parallelism > 0 {
+		c.Performance.Parallelism = o.Parallelism
+	}
+	if o.Timeout != "" {
+		c.Performance.Timeout = o.Timeout
+	}
+	if o.Budget != "" {
+		c.Performance.Budget = o.Budget
+	}
+}
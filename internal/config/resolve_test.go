@@ -0,0 +1,153 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	module := filepath.Join(root, "module")
+	nested := filepath.Join(module, "internal", "pkg")
+	if err := os.MkdirAll(nested, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(module, "go.mod"), "module example.com/m\n")
+
+	t.Run("no config", func(t *testing.T) {
+		got, err := Discover(nested)
+		if err != nil {
+			t.Fatalf("Discover() error = %v", err)
+		}
+		if got != "" {
+			t.Errorf("Discover() = %q, want empty", got)
+		}
+	})
+
+	t.Run("stops at module root", func(t *testing.T) {
+		writeFile(t, filepath.Join(root, ".regolint.yml"), "")
+		got, err := Discover(nested)
+		if err != nil {
+			t.Fatalf("Discover() error = %v", err)
+		}
+		if got != "" {
+			t.Errorf("Discover() = %q, want empty", got)
+		}
+	})
+
+	t.Run("finds yaml extension in parent", func(t *testing.T) {
+		want := filepath.Join(module, ".regolint.yaml")
+		writeFile(t, want, "")
+		got, err := Discover(nested)
+		if err != nil {
+			t.Fatalf("Discover() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("Discover() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("prefers yml over yaml", func(t *testing.T) {
+		want := filepath.Join(module, ".regolint.yml")
+		writeFile(t, want, "")
+		got, err := Discover(nested)
+		if err != nil {
+			t.Fatalf("Discover() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("Discover() = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".regolint.yml")
+	writeFile(t, path, `policies:
+  directory: ./policies
+  files:
+    - extra/security.rego
+rules:
+  disabled:
+    - TAG001
+output:
+  format: json
+`)
+
+	t.Run("relative paths resolve against config file", func(t *testing.T) {
+		cfg, err := Resolve(path, Overrides{})
+		if err != nil {
+			t.Fatalf("Resolve() error = %v", err)
+		}
+		if cfg.Path != path {
+			t.Errorf("Path = %q, want %q", cfg.Path, path)
+		}
+		if want := filepath.Join(dir, "policies"); cfg.Policies.Directory != want {
+			t.Errorf("Policies.Directory = %q, want %q", cfg.Policies.Directory, want)
+		}
+		if want := filepath.Join(dir, "extra", "security.rego"); cfg.Policies.Files[0] != want {
+			t.Errorf("Policies.Files[0] = %q, want %q", cfg.Policies.Files[0], want)
+		}
+		if cfg.Output.Format != "json" {
+			t.Errorf("Output.Format = %q, want %q", cfg.Output.Format, "json")
+		}
+	})
+
+	t.Run("overrides take precedence", func(t *testing.T) {
+		cfg, err := Resolve(path, Overrides{
+			PolicyDir:   "/custom",
+			Disabled:    []string{"SEC001"},
+			Format:      "sarif",
+			Parallelism: 8,
+		})
+		if err != nil {
+			t.Fatalf("Resolve() error = %v", err)
+		}
+		if cfg.Policies.Directory != "/custom" {
+			t.Errorf("Policies.Directory = %q, want %q", cfg.Policies.Directory, "/custom")
+		}
+		if !cfg.IsRuleDisabled("SEC001") || cfg.IsRuleDisabled("TAG001") {
+			t.Errorf("Rules.Disabled = %v, want [SEC001]", cfg.Rules.Disabled)
+		}
+		if cfg.Output.Format != "sarif" {
+			t.Errorf("Output.Format = %q, want %q", cfg.Output.Format, "sarif")
+		}
+		if cfg.Performance.Parallelism != 8 {
+			t.Errorf("Performance.Parallelism = %d, want 8", cfg.Performance.Parallelism)
+		}
+	})
+
+	t.Run("defaults without config file", func(t *testing.T) {
+		module := t.TempDir()
+		writeFile(t, filepath.Join(module, "go.mod"), "module example.com/m\n")
+		t.Chdir(module)
+
+		cfg, err := Resolve("", Overrides{})
+		if err != nil {
+			t.Fatalf("Resolve() error = %v", err)
+		}
+		if cfg.Path != "" {
+			t.Errorf("Path = %q, want empty", cfg.Path)
+		}
+		if cfg.Policies.Directory != DefaultPolicyDir {
+			t.Errorf("Policies.Directory = %q, want %q", cfg.Policies.Directory, DefaultPolicyDir)
+		}
+		if cfg.ShouldSkip("pkg/foo_test.go") {
+			t.Errorf("Exclude = %v, want test files analyzed", cfg.Exclude)
+		}
+	})
+
+	t.Run("missing explicit config", func(t *testing.T) {
+		if _, err := Resolve(filepath.Join(dir, "missing.yml"), Overrides{}); err == nil {
+			t.Error("expected error for missing config file")
+		}
+	})
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
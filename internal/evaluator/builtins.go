@@ -1,8 +1,11 @@
 package evaluator
 
 import (
+	"errors"
 	"regexp"
 
+	"github.com/burdzwastaken/regolint/internal/callgraph"
+	"github.com/burdzwastaken/regolint/internal/structtag"
 	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/open-policy-agent/opa/v1/ast"
 	"github.com/open-policy-agent/opa/v1/rego"
@@ -13,10 +16,15 @@ const maxRegexCacheSize = 1000
 
 var regexCache, _ = lru.New[string, *regexp.Regexp](maxRegexCacheSize)
 
+// errNoCallGraph stops the evaluation of a policy calling go.reachable when
+// no call graph was built.
+var errNoCallGraph = errors.New("no call graph was built; go.reachable needs the regolint CLI with analysis.call_graph set to auto, cha or vta")
+
 func init() {
 	registerBuiltins()
 }
 
+// nolint:PKG002 // one registration block per built-in
 func registerBuiltins() {
 	rego.RegisterBuiltin2(
 		&rego.Function{
@@ -108,6 +116,59 @@ func registerBuiltins() {
 			return ast.StringTerm(s), nil
 		},
 	)
+
+	rego.RegisterBuiltin2(
+		&rego.Function{
+			Name:             "go.reachable",
+			Description:      "Checks if a function or package transitively calls another in the static call graph",
+			Decl:             types.NewFunction(types.Args(types.S, types.S), types.B),
+			Memoize:          true,
+			Nondeterministic: false,
+		},
+		func(bctx rego.BuiltinContext, a, b *ast.Term) (*ast.Term, error) {
+			from, ok := a.Value.(ast.String)
+			if !ok {
+				return nil, nil
+			}
+			to, ok := b.Value.(ast.String)
+			if !ok {
+				return nil, nil
+			}
+
+			g := callgraph.FromContext(bctx.Context)
+			if g == nil {
+				// Answering false would pass every rule asking for a path.
+				return nil, rego.NewHaltError(errNoCallGraph)
+			}
+			return ast.BooleanTerm(g.Reachable(string(from), string(to))), nil
+		},
+	)
+
+	rego.RegisterBuiltin1(
+		&rego.Function{
+			Name:             "go.parse_struct_tag",
+			Description:      "Parses a struct tag into a tag_map of keys to name and options, and tag_errors",
+			Decl:             types.NewFunction(types.Args(types.S), types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+			Memoize:          true,
+			Nondeterministic: false,
+		},
+		func(_ rego.BuiltinContext, a *ast.Term) (*ast.Term, error) {
+			str, ok := a.Value.(ast.String)
+			if !ok {
+				return nil, nil
+			}
+
+			tagMap, tagErrors := structtag.Parse(string(str))
+			if tagErrors == nil {
+				tagErrors = []string{}
+			}
+			value, err := ast.InterfaceToValue(map[string]any{"tag_map": tagMap, "tag_errors": tagErrors})
+			if err != nil {
+				return nil, err
+			}
+			return ast.NewTerm(value), nil
+		},
+	)
 }
 
 func getCompiledRegex(pattern string) (*regexp.Regexp, error) {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/burdzwastaken/regolint/internal/model"
 	"github.com/open-policy-agent/opa/v1/ast"
@@ -33,14 +34,78 @@ func filteredCapabilities() *ast.Capabilities {
 	return caps
 }
 
+const (
+	// fileQuery evaluates file-scoped rules against a CodeContext.
+	fileQuery = "data.regolint.rules[category][rule].deny"
+	// packageQuery evaluates package-scoped rules against a PackageContext.
+	packageQuery = "data.regolint.rules[category][rule].deny_package"
+	// moduleQuery evaluates module-scoped rules against a ModuleContext.
+	moduleQuery = "data.regolint.rules[category][rule].deny_module"
+)
+
 // Evaluator wraps OPA and manages policy lifecycle.
 type Evaluator struct {
-	compiler *ast.Compiler
-	query    rego.PreparedEvalQuery
+	compiler     *ast.Compiler
+	query        rego.PreparedEvalQuery
+	packageQuery rego.PreparedEvalQuery
+	moduleQuery  rego.PreparedEvalQuery
+	metadata     map[string]ruleMetadata
+	rules        []string
+	timeout      time.Duration
 }
 
 // New creates a new Evaluator with the given policies.
-func New(policies map[string]string) (*Evaluator, error) {
+func New(policies map[string]string, opts ...Option) (*Evaluator, error) {
+	modules, err := parseModules(policies)
+	if err != nil {
+		return nil, err
+	}
+
+	capabilities := filteredCapabilities()
+	compiler := ast.NewCompiler().
+		WithStrict(true).
+		WithCapabilities(capabilities)
+	compiler.Compile(modules)
+	if compiler.Failed() {
+		return nil, fmt.Errorf("compiling policies: %v", compiler.Errors)
+	}
+
+	query, err := prepareQuery(compiler, fileQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgQuery, err := prepareQuery(compiler, packageQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	modQuery, err := prepareQuery(compiler, moduleQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := loadMetadata(compiler)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Evaluator{
+		compiler:     compiler,
+		query:        query,
+		packageQuery: pkgQuery,
+		moduleQuery:  modQuery,
+		metadata:     metadata,
+		rules:        rulePackages(compiler),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e, nil
+}
+
+func parseModules(policies map[string]string) (map[string]*ast.Module, error) {
 	modules := make(map[string]*ast.Module)
 
 	for name, content := range policies {
@@ -58,42 +123,70 @@ func New(policies map[string]string) (*Evaluator, error) {
 		modules[name] = parsed
 	}
 
-	capabilities := filteredCapabilities()
-	compiler := ast.NewCompiler().
-		WithStrict(true).
-		WithCapabilities(capabilities)
-	compiler.Compile(modules)
-	if compiler.Failed() {
-		return nil, fmt.Errorf("compiling policies: %v", compiler.Errors)
-	}
+	return modules, nil
+}
 
-	query, err := rego.New(
-		rego.Query("data.regolint.rules[category][rule].deny"),
+func prepareQuery(compiler *ast.Compiler, query string) (rego.PreparedEvalQuery, error) {
+	prepared, err := rego.New(
+		rego.Query(query),
 		rego.Compiler(compiler),
 	).PrepareForEval(context.Background())
 	if err != nil {
-		return nil, fmt.Errorf("preparing query: %w", err)
+		return rego.PreparedEvalQuery{}, fmt.Errorf("preparing query %s: %w", query, err)
 	}
+	return prepared, nil
+}
 
-	return &Evaluator{
-		compiler: compiler,
-		query:    query,
-	}, nil
+// Calls reports whether a loaded policy calls the built-in function name, such
+// as go.reachable.
+func (e *Evaluator) Calls(name string) bool {
+	found := false
+	for _, module := range e.compiler.Modules {
+		ast.WalkTerms(module, func(t *ast.Term) bool {
+			switch v := t.Value.(type) {
+			case ast.Call:
+				found = found || v[0].String() == name
+			case ast.Ref:
+				found = found || v.String() == name
+			}
+			return found
+		})
+	}
+	return found
 }
 
-// Evaluate runs all policies against the given CodeContext.
+// Evaluate runs all file-scoped rules (deny) against the given CodeContext.
 func (e *Evaluator) Evaluate(ctx context.Context, input *model.CodeContext) ([]model.Violation, error) {
-	return e.evaluate(ctx, input)
+	return e.evaluate(ctx, e.query, "deny", input)
 }
 
-// EvaluatePackage runs all policies against the given PackageContext.
+// EvaluatePackage runs all package-scoped rules (deny_package) against the given PackageContext.
 func (e *Evaluator) EvaluatePackage(ctx context.Context, input *model.PackageContext) ([]model.Violation, error) {
-	return e.evaluate(ctx, input)
+	return e.evaluate(ctx, e.packageQuery, "deny_package", input)
+}
+
+// EvaluateModule runs all module-scoped rules (deny_module) against the given
+// ModuleContext.
+func (e *Evaluator) EvaluateModule(ctx context.Context, input *model.ModuleContext) ([]model.Violation, error) {
+	return e.evaluate(ctx, e.moduleQuery, "deny_module", input)
 }
 
-func (e *Evaluator) evaluate(ctx context.Context, input any) ([]model.Violation, error) {
-	results, err := e.query.Eval(ctx, rego.EvalInput(input))
+func (e *Evaluator) evaluate(ctx context.Context, query rego.PreparedEvalQuery, entrypoint string, input any) ([]model.Violation, error) {
+	evalCtx := ctx
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		evalCtx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
+	results, err := query.Eval(evalCtx, rego.EvalInput(input))
 	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("evaluating policies: %w", ctx.Err())
+		}
+		if evalCtx.Err() != nil {
+			return nil, &TimeoutError{Rule: e.slowRule(ctx, entrypoint, input), Timeout: e.timeout}
+		}
 		return nil, fmt.Errorf("evaluating policies: %w", err)
 	}
 
@@ -104,8 +197,11 @@ func (e *Evaluator) extractViolations(results rego.ResultSet) ([]model.Violation
 	var violations []model.Violation
 
 	for _, result := range results {
+		meta := e.metadata[bindingKey(result.Bindings)]
 		for _, expr := range result.Expressions {
-			violations = append(violations, extractFromValue(expr.Value)...)
+			for _, v := range extractFromValue(expr.Value) {
+				violations = append(violations, meta.apply(v))
+			}
 		}
 	}
 
@@ -156,13 +252,12 @@ func parseViolation(v any) (model.Violation, error) {
 	if sev, ok := m["severity"].(string); ok {
 		violation.Severity = sev
 	}
+	if desc, ok := m["description"].(string); ok {
+		violation.Description = desc
+	}
 
 	if pos, ok := m["position"].(map[string]any); ok {
-		if file, ok := pos["file"].(string); ok {
-			violation.Position.File = file
-		}
-		violation.Position.Line = toInt(pos["line"])
-		violation.Position.Column = toInt(pos["column"])
+		violation.Position = parsePosition(pos)
 	}
 
 	if fix, ok := m["fix"].(map[string]any); ok {
@@ -187,18 +282,61 @@ func parseFix(m map[string]any) *model.Fix {
 					OldText: toString(editMap["old_text"]),
 				}
 				if pos, ok := editMap["position"].(map[string]any); ok {
-					edit.Position.File = toString(pos["file"])
-					edit.Position.Line = toInt(pos["line"])
-					edit.Position.Column = toInt(pos["column"])
+					edit.Position = parsePosition(pos)
+				}
+				if end, ok := editMap["end"].(map[string]any); ok {
+					pos := parsePosition(end)
+					edit.End = &pos
 				}
+				edit.Offset = toIntPtr(editMap["offset"])
+				edit.EndOffset = toIntPtr(editMap["end_offset"])
 				fix.Edits = append(fix.Edits, edit)
 			}
 		}
 	}
 
+	if ops, ok := m["ops"].([]any); ok {
+		for _, o := range ops {
+			if opMap, ok := o.(map[string]any); ok {
+				fix.Ops = append(fix.Ops, parseOp(opMap))
+			}
+		}
+	}
+
 	return fix
 }
 
+func parseOp(m map[string]any) model.FixOp {
+	op := model.FixOp{
+		Op:       toString(m["op"]),
+		Path:     toString(m["path"]),
+		Name:     toString(m["name"]),
+		NewName:  toString(m["new_name"]),
+		Text:     toString(m["text"]),
+		Key:      toString(m["key"]),
+		Value:    toString(m["value"]),
+		Function: toString(m["function"]),
+	}
+	if pos, ok := m["position"].(map[string]any); ok {
+		op.Position = parsePosition(pos)
+	}
+	if args, ok := m["args"].([]any); ok {
+		op.Args = make([]string, 0, len(args))
+		for _, arg := range args {
+			op.Args = append(op.Args, toString(arg))
+		}
+	}
+	return op
+}
+
+func parsePosition(m map[string]any) model.Position {
+	return model.Position{
+		File:   toString(m["file"]),
+		Line:   toInt(m["line"]),
+		Column: toInt(m["column"]),
+	}
+}
+
 func toString(v any) string {
 	if s, ok := v.(string); ok {
 		return s
@@ -221,3 +359,12 @@ func toInt(v any) int {
 		return 0
 	}
 }
+
+// toIntPtr distinguishes a missing number from zero.
+func toIntPtr(v any) *int {
+	if v == nil {
+		return nil
+	}
+	n := toInt(v)
+	return &n
+}
@@ -2,10 +2,17 @@ package evaluator_test
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/burdzwastaken/regolint/internal/callgraph"
 	"github.com/burdzwastaken/regolint/internal/evaluator"
 	"github.com/burdzwastaken/regolint/internal/model"
+	"github.com/burdzwastaken/regolint/internal/testenv"
+	"golang.org/x/tools/go/packages"
 )
 
 func TestEvaluatorBannedImports(t *testing.T) {
@@ -318,3 +325,343 @@ deny contains violation if {
 		t.Errorf("expected column 8, got %d", v.Position.Column)
 	}
 }
+
+func TestEvaluatorPackageRulesSeparateFromFileRules(t *testing.T) {
+	policy := `package regolint.rules.test.scopes
+
+deny contains violation if {
+	some fn in input.functions
+	violation := {"message": "file", "position": fn.position, "rule": "FILE001"}
+}
+
+deny_package contains violation if {
+	some fn in input.all_functions
+	violation := {"message": "package", "position": fn.position, "rule": "PKG001"}
+}
+`
+	eval, err := evaluator.New(map[string]string{"scopes.rego": policy})
+	if err != nil {
+		t.Fatalf("creating evaluator: %v", err)
+	}
+
+	fn := model.FunctionInfo{Name: "Foo", Position: model.Position{File: "foo.go", Line: 3}}
+
+	fileViolations, err := eval.Evaluate(context.Background(), &model.CodeContext{
+		Functions: []model.FunctionInfo{fn},
+	})
+	if err != nil {
+		t.Fatalf("evaluating file: %v", err)
+	}
+	if len(fileViolations) != 1 || fileViolations[0].Rule != "FILE001" {
+		t.Errorf("expected only FILE001 for file input, got %+v", fileViolations)
+	}
+
+	pkgViolations, err := eval.EvaluatePackage(context.Background(), &model.PackageContext{
+		AllFunctions: []model.FunctionInfo{fn},
+	})
+	if err != nil {
+		t.Fatalf("evaluating package: %v", err)
+	}
+	if len(pkgViolations) != 1 || pkgViolations[0].Rule != "PKG001" {
+		t.Errorf("expected only PKG001 for package input, got %+v", pkgViolations)
+	}
+}
+
+func TestEvaluatorModuleRules(t *testing.T) {
+	policy := `package regolint.rules.test.module
+
+deny contains violation if {
+	some fn in input.functions
+	violation := {"message": "file", "position": fn.position, "rule": "FILE001"}
+}
+
+deny_module contains violation if {
+	some edge in input.imports
+	startswith(edge.to, concat("/", [input.module_path, "internal"]))
+	not startswith(edge.from, concat("/", [input.module_path, "cmd"]))
+	violation := {"message": sprintf("%s imports %s", [edge.from, edge.to]), "position": edge.position, "rule": "MOD001"}
+}
+`
+	eval, err := evaluator.New(map[string]string{"module.rego": policy})
+	if err != nil {
+		t.Fatalf("creating evaluator: %v", err)
+	}
+
+	pos := model.Position{File: "/repo/pkg/a/a.go", Line: 3, Column: 2}
+	violations, err := eval.EvaluateModule(context.Background(), &model.ModuleContext{
+		ModulePath: "example.com/repo",
+		Imports: []model.ImportEdge{
+			{From: "example.com/repo/cmd/app", To: "example.com/repo/internal/api"},
+			{From: "example.com/repo/pkg/a", To: "example.com/repo/internal/api", Position: pos},
+		},
+	})
+	if err != nil {
+		t.Fatalf("evaluating module: %v", err)
+	}
+	if len(violations) != 1 || violations[0].Rule != "MOD001" {
+		t.Fatalf("expected only MOD001, got %+v", violations)
+	}
+	if violations[0].Position != pos {
+		t.Errorf("position = %+v, want %+v", violations[0].Position, pos)
+	}
+}
+
+func TestEvaluatorReachable(t *testing.T) {
+	policy := `package regolint.rules.test.reachable
+
+deny contains violation if {
+	some fn in input.functions
+	go.reachable(fn.qualified_name, "errors.is")
+	violation := {"message": fn.name, "position": fn.position, "rule": "CG001"}
+}
+`
+	eval, err := evaluator.New(map[string]string{"reachable.rego": policy})
+	if err != nil {
+		t.Fatalf("creating evaluator: %v", err)
+	}
+
+	input := &model.CodeContext{
+		Functions: []model.FunctionInfo{
+			{Name: "Is", QualifiedName: "errors.Is"},
+			{Name: "New", QualifiedName: "errors.New"},
+		},
+	}
+
+	if !eval.Calls("go.reachable") || eval.Calls("go.is_exported") {
+		t.Error("Calls() should report go.reachable only")
+	}
+
+	if _, err := eval.Evaluate(context.Background(), input); err == nil || !strings.Contains(err.Error(), "no call graph") {
+		t.Fatalf("evaluating without a call graph: err = %v, want no call graph error", err)
+	}
+
+	testenv.MustReadExportData(t)
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedImports | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo,
+	}
+	pkgs, err := packages.Load(cfg, "errors")
+	if err != nil {
+		t.Fatalf("loading packages: %v", err)
+	}
+	g, err := callgraph.Build(callgraph.Program(pkgs), callgraph.VTA)
+	if err != nil {
+		t.Fatalf("building call graph: %v", err)
+	}
+
+	violations, err := eval.Evaluate(callgraph.NewContext(context.Background(), g), input)
+	if err != nil {
+		t.Fatalf("evaluating with a call graph: %v", err)
+	}
+	if len(violations) != 1 || violations[0].Message != "Is" {
+		t.Errorf("expected only errors.Is to reach errors.is, got %+v", violations)
+	}
+}
+
+func TestEvaluatorParseStructTag(t *testing.T) {
+	policy := `package regolint.rules.test.tags
+
+deny contains violation if {
+	parsed := go.parse_struct_tag(input.package.doc)
+	parsed.tag_map.json.name == "id"
+	"omitempty" in parsed.tag_map.json.options
+	parsed.tag_map.db.options == []
+	parsed.tag_errors == []
+	violation := {"message": "parsed", "position": {"line": 1}, "rule": "TAG100"}
+}
+
+deny contains violation if {
+	parsed := go.parse_struct_tag(input.package.name)
+	some msg in parsed.tag_errors
+	violation := {"message": msg, "position": {"line": 2}, "rule": "TAG101"}
+}
+`
+	eval, err := evaluator.New(map[string]string{"tags.rego": policy})
+	if err != nil {
+		t.Fatalf("creating evaluator: %v", err)
+	}
+
+	violations, err := eval.Evaluate(context.Background(), &model.CodeContext{
+		Package: model.PackageInfo{Name: `json:id`, Doc: `json:"id,omitempty" db:"user_id"`},
+	})
+	if err != nil {
+		t.Fatalf("evaluating: %v", err)
+	}
+
+	got := make([]string, 0, len(violations))
+	for _, v := range violations {
+		got = append(got, v.Rule+" "+v.Message)
+	}
+	slices.Sort(got)
+	want := []string{"TAG100 parsed", "TAG101 bad syntax for struct tag value"}
+	if !slices.Equal(got, want) {
+		t.Errorf("violations = %q, want %q", got, want)
+	}
+}
+
+func TestEvaluatorTaintSpec(t *testing.T) {
+	policy := `package regolint.taint
+
+sources contains "net/http.(*Request).FormValue"
+
+sinks contains {"function": "database/sql.(*DB).Query", "args": [0]}
+
+sinks contains "os/exec.Command"
+
+sanitizers contains "strconv.Atoi"
+`
+	eval, err := evaluator.New(map[string]string{"taint.rego": policy})
+	if err != nil {
+		t.Fatalf("creating evaluator: %v", err)
+	}
+
+	spec, err := eval.TaintSpec(context.Background())
+	if err != nil {
+		t.Fatalf("loading taint spec: %v", err)
+	}
+	if !slices.Equal(spec.Sources, []string{"net/http.(*Request).FormValue"}) {
+		t.Errorf("sources = %v", spec.Sources)
+	}
+	if !slices.Equal(spec.Sanitizers, []string{"strconv.Atoi"}) {
+		t.Errorf("sanitizers = %v", spec.Sanitizers)
+	}
+	if len(spec.Sinks) != 2 {
+		t.Fatalf("expected 2 sinks, got %+v", spec.Sinks)
+	}
+	for _, sink := range spec.Sinks {
+		switch sink.Function {
+		case "database/sql.(*DB).Query":
+			if !slices.Equal(sink.Args, []int{0}) {
+				t.Errorf("Query args = %v, want [0]", sink.Args)
+			}
+		case "os/exec.Command":
+			if sink.Args != nil {
+				t.Errorf("Command args = %v, want all", sink.Args)
+			}
+		default:
+			t.Errorf("unexpected sink %+v", sink)
+		}
+	}
+
+	empty, err := evaluator.New(map[string]string{"safe.rego": "package regolint.rules.test.safe\n"})
+	if err != nil {
+		t.Fatalf("creating evaluator: %v", err)
+	}
+	if spec, err := empty.TaintSpec(context.Background()); err != nil || !spec.Empty() {
+		t.Errorf("expected an empty spec without a regolint.taint package, got %+v, %v", spec, err)
+	}
+}
+
+func TestEvaluatorMetadataDefaults(t *testing.T) {
+	policy := `package regolint.rules.test.metadata
+
+metadata := {
+	"id": "META001",
+	"severity": "warning",
+	"description": "Checks metadata defaults",
+}
+
+deny contains violation if {
+	some fn in input.functions
+	fn.name == "implicit"
+	violation := {"message": "implicit", "position": fn.position}
+}
+
+deny contains violation if {
+	some fn in input.functions
+	fn.name == "explicit"
+	violation := {"message": "explicit", "position": fn.position, "rule": "META002", "severity": "info"}
+}
+
+deny contains violation if {
+	some fn in input.functions
+	fn.name == "same"
+	violation := {"message": "same", "position": fn.position, "rule": "META001"}
+}
+`
+	eval, err := evaluator.New(map[string]string{"metadata.rego": policy})
+	if err != nil {
+		t.Fatalf("creating evaluator: %v", err)
+	}
+
+	violations, err := eval.Evaluate(context.Background(), &model.CodeContext{
+		Functions: []model.FunctionInfo{
+			{Name: "implicit", Position: model.Position{Line: 1}},
+			{Name: "explicit", Position: model.Position{Line: 2}},
+			{Name: "same", Position: model.Position{Line: 3}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("evaluating: %v", err)
+	}
+
+	if len(violations) != 3 {
+		t.Fatalf("expected 3 violations, got %d", len(violations))
+	}
+
+	byMessage := make(map[string]model.Violation)
+	for _, v := range violations {
+		byMessage[v.Message] = v
+	}
+
+	implicit := byMessage["implicit"]
+	if implicit.Rule != "META001" || implicit.Severity != "warning" || implicit.Description != "Checks metadata defaults" {
+		t.Errorf("expected metadata defaults, got %+v", implicit)
+	}
+
+	explicit := byMessage["explicit"]
+	if explicit.Rule != "META002" || explicit.Severity != "info" {
+		t.Errorf("expected explicit fields to be kept, got %+v", explicit)
+	}
+	if explicit.Description != "" {
+		t.Errorf("expected no description for another rule, got %q", explicit.Description)
+	}
+
+	same := byMessage["same"]
+	if same.Severity != "warning" || same.Description != "Checks metadata defaults" {
+		t.Errorf("expected metadata defaults for the metadata rule, got %+v", same)
+	}
+}
+
+func TestEvaluatorTimeout(t *testing.T) {
+	policies := map[string]string{
+		"fast.rego": `package regolint.rules.test.fast
+
+deny contains violation if {
+	some fn in input.functions
+	violation := {"message": "fast", "position": fn.position, "rule": "FAST001"}
+}
+`,
+		"slow.rego": `package regolint.rules.test.slow
+
+metadata := {"id": "SLOW001"}
+
+deny contains violation if {
+	some i in numbers.range(1, 100000)
+	some j in numbers.range(1, 100000)
+	i * j == -1
+	violation := {"message": "slow", "position": {"line": 1}, "rule": metadata.id}
+}
+`,
+	}
+
+	eval, err := evaluator.New(policies, evaluator.WithTimeout(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("creating evaluator: %v", err)
+	}
+
+	_, err = eval.Evaluate(context.Background(), &model.CodeContext{})
+	if err == nil {
+		t.Fatal("expected timeout error")
+	}
+
+	var timeoutErr *evaluator.TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected TimeoutError, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("expected error to match context.DeadlineExceeded")
+	}
+	if !strings.Contains(timeoutErr.Rule, "SLOW001") {
+		t.Errorf("expected slow rule to be identified, got %q", timeoutErr.Rule)
+	}
+}
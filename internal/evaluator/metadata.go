@@ -0,0 +1,72 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/burdzwastaken/regolint/internal/model"
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/rego"
+)
+
+// metadataQuery collects the metadata object declared by each rule package.
+const metadataQuery = "data.regolint.rules[category][rule].metadata"
+
+// ruleMetadata is the metadata object a rule package declares alongside its deny rules.
+type ruleMetadata struct {
+	id          string
+	severity    string
+	description string
+}
+
+// apply fills in the fields a violation omitted from the rule's metadata. A
+// violation that names a different rule keeps only the fields it sets.
+func (m ruleMetadata) apply(v model.Violation) model.Violation {
+	if v.Rule == "" {
+		v.Rule = m.id
+	}
+	if v.Rule != m.id {
+		return v
+	}
+	if v.Severity == "" {
+		v.Severity = m.severity
+	}
+	if v.Description == "" {
+		v.Description = m.description
+	}
+	return v
+}
+
+func loadMetadata(compiler *ast.Compiler) (map[string]ruleMetadata, error) {
+	results, err := rego.New(
+		rego.Query(metadataQuery),
+		rego.Compiler(compiler),
+	).Eval(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("loading rule metadata: %w", err)
+	}
+
+	metadata := make(map[string]ruleMetadata, len(results))
+	for _, result := range results {
+		if len(result.Expressions) == 0 {
+			continue
+		}
+		m, ok := result.Expressions[0].Value.(map[string]any)
+		if !ok {
+			continue
+		}
+		metadata[bindingKey(result.Bindings)] = ruleMetadata{
+			id:          toString(m["id"]),
+			severity:    toString(m["severity"]),
+			description: toString(m["description"]),
+		}
+	}
+
+	return metadata, nil
+}
+
+// bindingKey identifies a rule package from the category and rule variables
+// bound by the rule queries.
+func bindingKey(bindings rego.Vars) string {
+	return toString(bindings["category"]) + "." + toString(bindings["rule"])
+}
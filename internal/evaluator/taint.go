@@ -0,0 +1,77 @@
+package evaluator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/burdzwastaken/regolint/internal/taint"
+	"github.com/open-policy-agent/opa/v1/rego"
+)
+
+// taintQuery collects the sources, sinks and sanitizers declared by policies.
+const taintQuery = "data.regolint.taint"
+
+// TaintSpec returns the sources, sinks and sanitizers declared by policies in
+// the regolint.taint package. Sources and sanitizers are sets of function
+// names; a sink is a function name or an object with a function and the
+// indexes of its checked args.
+func (e *Evaluator) TaintSpec(ctx context.Context) (taint.Spec, error) {
+	results, err := rego.New(
+		rego.Query(taintQuery),
+		rego.Compiler(e.compiler),
+	).Eval(ctx)
+	if err != nil {
+		return taint.Spec{}, fmt.Errorf("loading taint specification: %w", err)
+	}
+	if len(results) == 0 || len(results[0].Expressions) == 0 {
+		return taint.Spec{}, nil
+	}
+	m, ok := results[0].Expressions[0].Value.(map[string]any)
+	if !ok {
+		return taint.Spec{}, nil
+	}
+
+	spec := taint.Spec{
+		Sources:    toStrings(m["sources"]),
+		Sanitizers: toStrings(m["sanitizers"]),
+	}
+	items, _ := m["sinks"].([]any)
+	for _, item := range items {
+		sink, err := parseSink(item)
+		if err != nil {
+			return taint.Spec{}, fmt.Errorf("loading taint specification: %w", err)
+		}
+		spec.Sinks = append(spec.Sinks, sink)
+	}
+	return spec, nil
+}
+
+func parseSink(v any) (taint.Sink, error) {
+	if name, ok := v.(string); ok {
+		return taint.Sink{Function: name}, nil
+	}
+
+	m, ok := v.(map[string]any)
+	if !ok || toString(m["function"]) == "" {
+		return taint.Sink{}, errors.New("sink must be a function name or an object with a function")
+	}
+	sink := taint.Sink{Function: toString(m["function"])}
+	if args, ok := m["args"].([]any); ok {
+		for _, arg := range args {
+			sink.Args = append(sink.Args, toInt(arg))
+		}
+	}
+	return sink, nil
+}
+
+func toStrings(v any) []string {
+	items, _ := v.([]any)
+	strs := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+	return strs
+}
@@ -0,0 +1,93 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/rego"
+)
+
+// Option configures an Evaluator.
+type Option func(*Evaluator)
+
+// WithTimeout bounds every Evaluate, EvaluatePackage and EvaluateModule call.
+// A zero duration disables the limit.
+func WithTimeout(d time.Duration) Option {
+	return func(e *Evaluator) {
+		e.timeout = d
+	}
+}
+
+// TimeoutError reports an evaluation that exceeded the configured timeout.
+// nolint:TAG001 // not serialized
+type TimeoutError struct {
+	// Rule identifies the rule package that was still running when the
+	// timeout expired, if it could be determined.
+	Rule    string
+	Timeout time.Duration
+}
+
+// Error names the rule that timed out, if known.
+func (e *TimeoutError) Error() string {
+	if e.Rule == "" {
+		return fmt.Sprintf("evaluation exceeded timeout of %s", e.Timeout)
+	}
+	return fmt.Sprintf("rule %s exceeded timeout of %s", e.Rule, e.Timeout)
+}
+
+// Unwrap lets callers match timeouts with errors.Is(err, context.DeadlineExceeded).
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+var rulesRoot = ast.MustParseRef("data.regolint.rules")
+
+// rulePackages lists the category.rule keys of all loaded rule packages in a
+// stable order.
+func rulePackages(compiler *ast.Compiler) []string {
+	var keys []string
+	for _, module := range compiler.Modules {
+		path := module.Package.Path
+		if len(path) != len(rulesRoot)+2 || !path.HasPrefix(rulesRoot) {
+			continue
+		}
+		category, ok1 := path[3].Value.(ast.String)
+		rule, ok2 := path[4].Value.(ast.String)
+		if ok1 && ok2 {
+			keys = append(keys, string(category)+"."+string(rule))
+		}
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// slowRule re-evaluates each rule package on its own, sharing a single timeout
+// budget, and returns the first one that fails to finish. It is only used to
+// explain a timeout, so the extra cost is paid on the failure path only.
+func (e *Evaluator) slowRule(ctx context.Context, entrypoint string, input any) string {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	for _, key := range e.rules {
+		category, rule, _ := strings.Cut(key, ".")
+		query := fmt.Sprintf("data.regolint.rules[%q][%q].%s", category, rule, entrypoint)
+
+		_, err := rego.New(
+			rego.Query(query),
+			rego.Compiler(e.compiler),
+			rego.Input(input),
+		).Eval(ctx)
+		if err != nil && ctx.Err() != nil {
+			if id := e.metadata[key].id; id != "" {
+				return fmt.Sprintf("%s (%s)", id, key)
+			}
+			return key
+		}
+	}
+
+	return ""
+}
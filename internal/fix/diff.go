@@ -0,0 +1,218 @@
+package fix
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// lineOp is one line of a line-based diff.
+type lineOp struct {
+	kind byte // ' ', '-' or '+'
+	text []byte
+}
+
+// WriteDiff writes a unified diff from the original to the fixed content of f,
+// labelling the two sides oldName and newName.
+func (f *File) WriteDiff(w io.Writer, oldName, newName string) error {
+	ops := diffLines(splitLines(f.Original), splitLines(f.Fixed))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks(ops) {
+		writeHunk(&buf, ops, h)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// hunk is a range of ops together with the lines it starts at on either side.
+type hunk struct {
+	start, end       int
+	oldLine, newLine int
+}
+
+// hunks groups changes that are within 2*diffContext lines of each other.
+func hunks(ops []lineOp) []hunk {
+	var result []hunk
+	oldLine, newLine := 0, 0
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		before := min(i, diffContext)
+		h := hunk{start: i - before, oldLine: oldLine - before, newLine: newLine - before}
+
+		end, unchanged := i, 0
+		for ; end < len(ops) && unchanged <= 2*diffContext; end++ {
+			switch ops[end].kind {
+			case ' ':
+				unchanged++
+				oldLine++
+				newLine++
+			case '-':
+				unchanged = 0
+				oldLine++
+			case '+':
+				unchanged = 0
+				newLine++
+			}
+		}
+
+		// Trim trailing context back to diffContext lines.
+		trim := max(unchanged-diffContext, 0)
+		h.end = end - trim
+		oldLine -= trim
+		newLine -= trim
+		i = h.end
+
+		result = append(result, h)
+	}
+
+	return result
+}
+
+func writeHunk(buf *bytes.Buffer, ops []lineOp, h hunk) {
+	oldCount, newCount := 0, 0
+	for _, op := range ops[h.start:h.end] {
+		if op.kind != '+' {
+			oldCount++
+		}
+		if op.kind != '-' {
+			newCount++
+		}
+	}
+
+	fmt.Fprintf(buf, "@@ -%s +%s @@\n", hunkRange(h.oldLine, oldCount), hunkRange(h.newLine, newCount))
+	for _, op := range ops[h.start:h.end] {
+		buf.WriteByte(op.kind)
+		buf.Write(op.text)
+		if !bytes.HasSuffix(op.text, []byte("\n")) {
+			buf.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the 1-based start and line count of one side of a hunk.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return strconv.Itoa(start + 1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
+
+func splitLines(src []byte) [][]byte {
+	if len(src) == 0 {
+		return nil
+	}
+	lines := bytes.SplitAfter(src, []byte("\n"))
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes a shortest edit script between a and b with Myers'
+// algorithm, after trimming the common prefix and suffix.
+func diffLines(a, b [][]byte) []lineOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && bytes.Equal(a[prefix], b[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		bytes.Equal(a[len(a)-1-suffix], b[len(b)-1-suffix]) {
+		suffix++
+	}
+
+	var ops []lineOp
+	for _, line := range a[:prefix] {
+		ops = append(ops, lineOp{' ', line})
+	}
+	ops = append(ops, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, lineOp{' ', line})
+	}
+	return ops
+}
+
+func myers(a, b [][]byte) []lineOp {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, slices.Clone(v))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && bytes.Equal(a[x], b[y]) {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b, offset)
+			}
+		}
+	}
+
+	return nil
+}
+
+// backtrack walks the recorded Myers frontiers from the end of both inputs
+// back to the start, emitting the edit script in reverse.
+func backtrack(trace [][]int, a, b [][]byte, offset int) []lineOp {
+	var ops []lineOp
+	x, y := len(a), len(b)
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		prevK := k - 1
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, lineOp{' ', a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, lineOp{'+', b[y-1]})
+			} else {
+				ops = append(ops, lineOp{'-', a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	slices.Reverse(ops)
+	return ops
+}
@@ -0,0 +1,281 @@
+// Package fix applies the text edits suggested by policy violations.
+package fix
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"go/format"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/burdzwastaken/regolint/internal/model"
+)
+
+// File is a file whose content changes once fixes are applied.
+// nolint:TAG001 // not serialized
+type File struct {
+	Path     string
+	Original []byte
+	Fixed    []byte
+}
+
+// Skipped records a fix that could not be applied and why.
+// nolint:TAG001 // not serialized
+type Skipped struct {
+	Violation model.Violation
+	Reason    string
+}
+
+// Result is the outcome of applying the fixes suggested by a set of violations.
+// nolint:TAG001 // not serialized
+type Result struct {
+	// Files holds the changed files, sorted by path.
+	Files []*File
+	// Fixed holds the violations whose fix was applied.
+	Fixed []model.Violation
+	// Remaining holds the violations without a fix or whose fix was skipped.
+	Remaining []model.Violation
+	Skipped   []Skipped
+}
+
+// edit is a FixEdit resolved to a byte range of a file.
+type edit struct {
+	path    string
+	start   int
+	end     int
+	newText string
+}
+
+// fileState tracks the content of a file and the edits accepted for it.
+type fileState struct {
+	src   []byte
+	edits []edit
+}
+
+// Apply computes the result of applying the fix edits of violations, in
+// order. A violation's edits are applied together or not at all: its fix is
+// skipped if any old_text does not match the file or if it overlaps an edit
+// already accepted from another violation. Changed files are reformatted with
+// gofmt. Nothing is written to disk; see Result.Write.
+func Apply(violations []model.Violation) (*Result, error) {
+	files := make(map[string]*fileState)
+	result := &Result{}
+	fixed := make([]bool, len(violations))
+	owned := make(map[int][]edit)
+
+	for i, v := range violations {
+		if v.Fix == nil || len(v.Fix.Edits) == 0 {
+			continue
+		}
+
+		edits, reason, err := resolve(v, files)
+		if err != nil {
+			return nil, err
+		}
+		if reason == "" {
+			reason = accept(edits, files)
+		}
+		if reason != "" {
+			result.Skipped = append(result.Skipped, Skipped{Violation: v, Reason: reason})
+			continue
+		}
+
+		owned[i] = edits
+		fixed[i] = true
+	}
+
+	result.format(files, violations, owned, fixed)
+
+	for i, v := range violations {
+		if fixed[i] {
+			result.Fixed = append(result.Fixed, v)
+		} else {
+			result.Remaining = append(result.Remaining, v)
+		}
+	}
+
+	return result, nil
+}
+
+// format gofmts the edited files, recording those that change. The fixes
+// with edits to a file that no longer parses are skipped, and their edits to
+// other files are dropped with them, so a fix is never applied in part.
+func (r *Result) format(files map[string]*fileState, violations []model.Violation, owned map[int][]edit, fixed []bool) {
+	paths := slices.Sorted(maps.Keys(files))
+	formatted := make(map[string][]byte)
+
+	// Skipping a fix changes the files it edits, so repeat until every
+	// remaining fix formats.
+	for changed := true; changed; {
+		changed = false
+		clear(formatted)
+		for _, path := range paths {
+			edits, owners := fileEdits(path, owned, fixed)
+			if len(edits) == 0 {
+				continue
+			}
+
+			out, err := format.Source(splice(files[path].src, edits))
+			if err != nil {
+				for _, i := range owners {
+					fixed[i] = false
+					r.Skipped = append(r.Skipped, Skipped{
+						Violation: violations[i],
+						Reason:    fmt.Sprintf("fixed %s does not parse: %v", filepath.Base(path), err),
+					})
+				}
+				changed = true
+				break
+			}
+			formatted[path] = out
+		}
+	}
+
+	for _, path := range paths {
+		if out, ok := formatted[path]; ok && !bytes.Equal(out, files[path].src) {
+			r.Files = append(r.Files, &File{Path: path, Original: files[path].src, Fixed: out})
+		}
+	}
+}
+
+// fileEdits returns the edits to path of the violations still fixed, without
+// duplicates, and the indexes of those violations.
+func fileEdits(path string, owned map[int][]edit, fixed []bool) ([]edit, []int) {
+	var edits []edit
+	var owners []int
+	for i := range fixed {
+		if !fixed[i] {
+			continue
+		}
+		for _, e := range owned[i] {
+			if e.path != path {
+				continue
+			}
+			if !slices.Contains(owners, i) {
+				owners = append(owners, i)
+			}
+			if !slices.Contains(edits, e) {
+				edits = append(edits, e)
+			}
+		}
+	}
+	return edits, owners
+}
+
+// Write saves the fixed files, preserving their permissions.
+func (r *Result) Write() error {
+	for _, f := range r.Files {
+		info, err := os.Stat(f.Path)
+		if err != nil {
+			return fmt.Errorf("writing fix: %w", err)
+		}
+		if err := os.WriteFile(f.Path, f.Fixed, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("writing fix: %w", err)
+		}
+	}
+	return nil
+}
+
+// resolve maps the edits of a violation to byte ranges, loading the files
+// they touch. Edits without a file apply to the violation's file, and
+// relative files are taken to be siblings of it, matching the base names
+// reported in policy positions. It returns the reason the fix cannot be
+// applied if an edit's range does not exist in the file.
+func resolve(v model.Violation, files map[string]*fileState) ([]edit, string, error) {
+	edits := make([]edit, 0, len(v.Fix.Edits))
+
+	for _, fe := range v.Fix.Edits {
+		path := fe.Position.File
+		switch {
+		case path == "":
+			path = v.Position.File
+		case !filepath.IsAbs(path):
+			path = filepath.Join(filepath.Dir(v.Position.File), filepath.Base(path))
+		}
+
+		state, ok := files[path]
+		if !ok {
+			src, err := os.ReadFile(filepath.Clean(path))
+			if err != nil {
+				return nil, "", fmt.Errorf("reading %s: %w", path, err)
+			}
+			state = &fileState{src: src}
+			files[path] = state
+		}
+
+		resolved, err := Resolve(state.src, fe)
+		if err != nil {
+			return nil, fmt.Sprintf("%s: %v", filepath.Base(path), err), nil
+		}
+
+		edits = append(edits, edit{
+			path:    path,
+			start:   *resolved.Offset,
+			end:     *resolved.EndOffset,
+			newText: resolved.NewText,
+		})
+	}
+
+	return edits, "", nil
+}
+
+// accept checks edits against each other and against the edits already
+// accepted, recording them if they can be applied. It returns the reason the
+// fix was rejected, or an empty string.
+func accept(edits []edit, files map[string]*fileState) string {
+	var pending []edit
+	for _, e := range edits {
+		state := files[e.path]
+		if slices.Contains(state.edits, e) {
+			// Another violation already suggested the identical edit.
+			continue
+		}
+		for _, other := range slices.Concat(state.edits, pending) {
+			if other.path == e.path && overlaps(e, other) {
+				return fmt.Sprintf("edit at %s overlaps another fix", position(state.src, e.start, e.path))
+			}
+		}
+		pending = append(pending, e)
+	}
+
+	for _, e := range pending {
+		state := files[e.path]
+		state.edits = append(state.edits, e)
+	}
+	return ""
+}
+
+// overlaps reports whether two edits touch the same bytes. Insertions at the
+// same offset overlap too, since their order would be ambiguous.
+func overlaps(a, b edit) bool {
+	if a.start == a.end && b.start == b.end {
+		return a.start == b.start
+	}
+	return a.start < b.end && b.start < a.end
+}
+
+// splice applies non-overlapping edits to src.
+func splice(src []byte, edits []edit) []byte {
+	slices.SortFunc(edits, func(a, b edit) int {
+		return cmp.Or(cmp.Compare(a.start, b.start), cmp.Compare(a.end, b.end))
+	})
+
+	var buf bytes.Buffer
+	last := 0
+	for _, e := range edits {
+		buf.Write(src[last:e.start])
+		buf.WriteString(e.newText)
+		last = e.end
+	}
+	buf.Write(src[last:])
+	return buf.Bytes()
+}
+
+func position(src []byte, offset int, path string) string {
+	line := bytes.Count(src[:offset], []byte("\n")) + 1
+	column := offset - bytes.LastIndexByte(src[:offset], '\n')
+	return fmt.Sprintf("%s:%d:%d", filepath.Base(path), line, column)
+}
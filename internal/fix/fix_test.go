@@ -0,0 +1,298 @@
+package fix
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/burdzwastaken/regolint/internal/model"
+)
+
+const source = `package p
+
+func a() {
+	x := oldName()
+	_ = x
+}
+
+func oldName() int { return 1 }
+`
+
+func violation(t *testing.T, path, rule string, edits ...model.FixEdit) model.Violation {
+	t.Helper()
+	return model.Violation{
+		Rule:     rule,
+		Position: model.Position{File: path, Line: 1, Column: 1},
+		Fix:      &model.Fix{Edits: edits},
+	}
+}
+
+func rename(line, column int) model.FixEdit {
+	return model.FixEdit{
+		Position: model.Position{File: "p.go", Line: line, Column: column},
+		OldText:  "oldName",
+		NewText:  "newName",
+	}
+}
+
+func writeSource(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "p.go")
+	if err := os.WriteFile(path, []byte(source), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestApply(t *testing.T) {
+	path := writeSource(t)
+
+	result, err := Apply([]model.Violation{
+		violation(t, path, "NAME001", rename(4, 7), rename(8, 6)),
+		{Rule: "DOC001", Position: model.Position{File: path, Line: 8, Column: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	if len(result.Files) != 1 {
+		t.Fatalf("Files = %d, want 1", len(result.Files))
+	}
+	want := strings.ReplaceAll(source, "oldName", "newName")
+	if got := string(result.Files[0].Fixed); got != want {
+		t.Errorf("Fixed =\n%s\nwant\n%s", got, want)
+	}
+	if len(result.Fixed) != 1 || result.Fixed[0].Rule != "NAME001" {
+		t.Errorf("Fixed = %v, want NAME001", result.Fixed)
+	}
+	if len(result.Remaining) != 1 || result.Remaining[0].Rule != "DOC001" {
+		t.Errorf("Remaining = %v, want DOC001", result.Remaining)
+	}
+
+	if err := result.Write(); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got, _ := os.ReadFile(filepath.Clean(path)); string(got) != want {
+		t.Errorf("file on disk =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestApplySkips(t *testing.T) {
+	tests := []struct {
+		name       string
+		violations func(path string) []model.Violation
+		wantFixed  []string
+		wantReason string
+	}{
+		{
+			name: "old text mismatch",
+			violations: func(path string) []model.Violation {
+				return []model.Violation{violation(t, path, "NAME001", rename(4, 7), rename(8, 1))}
+			},
+			wantReason: "p.go: old_text does not match 8:1",
+		},
+		{
+			name: "overlap between rules",
+			violations: func(path string) []model.Violation {
+				return []model.Violation{
+					violation(t, path, "NAME001", rename(4, 7)),
+					violation(t, path, "NAME002", model.FixEdit{
+						Position: model.Position{Line: 4, Column: 10},
+						OldText:  "Name()",
+						NewText:  "Name2()",
+					}),
+				}
+			},
+			wantFixed:  []string{"NAME001"},
+			wantReason: "edit at p.go:4:10 overlaps another fix",
+		},
+		{
+			name: "insertions at the same offset",
+			violations: func(path string) []model.Violation {
+				return []model.Violation{
+					violation(t, path, "DOC001", model.FixEdit{Position: model.Position{Line: 8, Column: 1}, NewText: "// a\n"}),
+					violation(t, path, "DOC002", model.FixEdit{Position: model.Position{Line: 8, Column: 1}, NewText: "// b\n"}),
+				}
+			},
+			wantFixed:  []string{"DOC001"},
+			wantReason: "edit at p.go:8:1 overlaps another fix",
+		},
+		{
+			name: "identical edits are applied once",
+			violations: func(path string) []model.Violation {
+				return []model.Violation{
+					violation(t, path, "NAME001", rename(8, 6)),
+					violation(t, path, "NAME001", rename(8, 6)),
+				}
+			},
+			wantFixed: []string{"NAME001", "NAME001"},
+		},
+		{
+			name: "result does not parse",
+			violations: func(path string) []model.Violation {
+				return []model.Violation{violation(t, path, "SYN001", model.FixEdit{
+					Position: model.Position{Line: 8, Column: 1},
+					OldText:  "func",
+					NewText:  "fun",
+				})}
+			},
+			wantReason: "does not parse",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeSource(t)
+
+			result, err := Apply(tt.violations(path))
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+
+			var fixed []string
+			for _, v := range result.Fixed {
+				fixed = append(fixed, v.Rule)
+			}
+			if strings.Join(fixed, ",") != strings.Join(tt.wantFixed, ",") {
+				t.Errorf("Fixed = %v, want %v", fixed, tt.wantFixed)
+			}
+
+			if tt.wantReason == "" {
+				if len(result.Skipped) != 0 {
+					t.Errorf("Skipped = %v, want none", result.Skipped)
+				}
+				return
+			}
+			if len(result.Skipped) != 1 || !strings.Contains(result.Skipped[0].Reason, tt.wantReason) {
+				t.Errorf("Skipped = %v, want reason containing %q", result.Skipped, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestApplyMultiFileFixIsAllOrNothing(t *testing.T) {
+	path := writeSource(t)
+	other := filepath.Join(filepath.Dir(path), "q.go")
+	if err := os.WriteFile(other, []byte("package p\n\nfunc q() {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Apply([]model.Violation{
+		violation(t, path, "NAME001", rename(8, 6), model.FixEdit{
+			Position: model.Position{File: "q.go", Line: 3, Column: 1},
+			OldText:  "func",
+			NewText:  "fun",
+		}),
+		violation(t, path, "NAME002", rename(4, 7)),
+	})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	if len(result.Skipped) != 1 || result.Skipped[0].Violation.Rule != "NAME001" || !strings.Contains(result.Skipped[0].Reason, "q.go does not parse") {
+		t.Errorf("Skipped = %v, want NAME001 because q.go does not parse", result.Skipped)
+	}
+	if len(result.Files) != 1 || result.Files[0].Path != path {
+		t.Fatalf("Files = %v, want only p.go", result.Files)
+	}
+	// NAME001's edit to p.go is dropped with its edit to q.go.
+	fixed := string(result.Files[0].Fixed)
+	if !strings.Contains(fixed, "x := newName()") || !strings.Contains(fixed, "func oldName()") {
+		t.Errorf("fixed p.go = %s, want only NAME002 applied", fixed)
+	}
+}
+
+func TestApplyFormats(t *testing.T) {
+	path := writeSource(t)
+
+	result, err := Apply([]model.Violation{violation(t, path, "FMT001", model.FixEdit{
+		Position: model.Position{Line: 5, Column: 2},
+		OldText:  "_ = x",
+		NewText:  "_    =    x",
+	})})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	// gofmt undoes the whitespace change, leaving nothing to write.
+	if len(result.Files) != 0 {
+		t.Errorf("Files = %d, want 0", len(result.Files))
+	}
+}
+
+func TestWriteDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		fixed    string
+		want     string
+	}{
+		{
+			name:     "single change with context",
+			original: "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			fixed:    "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			want: `--- a/p.go
++++ b/p.go
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`,
+		},
+		{
+			name:     "distant changes in separate hunks",
+			original: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			fixed:    "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n",
+			want: `--- a/p.go
++++ b/p.go
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -7,4 +7,4 @@
+ 7
+ 8
+ 9
+-10
++ten
+`,
+		},
+		{
+			name:     "insertion and missing newline",
+			original: "a\nb",
+			fixed:    "a\nx\nb\n",
+			want: `--- a/p.go
++++ b/p.go
+@@ -1,2 +1,3 @@
+ a
+-b
+\ No newline at end of file
++x
++b
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &File{Original: []byte(tt.original), Fixed: []byte(tt.fixed)}
+
+			var buf bytes.Buffer
+			if err := f.WriteDiff(&buf, "a/p.go", "b/p.go"); err != nil {
+				t.Fatalf("WriteDiff() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("WriteDiff() =\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,511 @@
+package fix
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/burdzwastaken/regolint/internal/model"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// Package is the syntax and type information fix operations are lowered
+// against. TypesInfo is only needed by rename_identifier. ReadFile loads the
+// source of the files, which go/token does not retain; it defaults to
+// os.ReadFile. A Package may be shared by concurrent Lower calls.
+// nolint:TAG001 // not serialized
+type Package struct {
+	Fset      *token.FileSet
+	Files     []*ast.File
+	Types     *types.Package
+	TypesInfo *types.Info
+	ReadFile  func(filename string) ([]byte, error)
+
+	mu      sync.Mutex
+	sources map[string][]byte
+}
+
+// Lower resolves the text edits of violations' fixes against the source, as
+// Resolve does, and replaces their fix operations with the edits they stand
+// for. Edits and operations name their file by base name or path, and default
+// to the file of their violation or, failing that, to defaultFile. A fix that
+// cannot be applied as written, such as one whose old_text is stale or whose
+// operation does not fit the code, is dropped with a warning naming its rule;
+// the violation is kept. Violations share their Fix with the evaluator's
+// results, so lowered fixes are copies.
+func (p *Package) Lower(violations []model.Violation, defaultFile string) {
+	for i, v := range violations {
+		if v.Fix == nil {
+			continue
+		}
+		if v.Position.File == "" {
+			v.Position.File = defaultFile
+		}
+
+		fixed, err := p.lowerFix(v)
+		if err != nil {
+			log.Printf("[regolint] warning: rule %s: dropping fix: %v", v.Rule, err)
+		}
+		violations[i].Fix = fixed
+	}
+}
+
+// lowerFix returns a copy of the fix of v with its edits resolved and its
+// operations lowered, or an error if any of them cannot be.
+func (p *Package) lowerFix(v model.Violation) (*model.Fix, error) {
+	edits, err := p.resolveEdits(v)
+	if err != nil {
+		return nil, err
+	}
+
+	lowered, err := lowerOps(p, v)
+	if err != nil {
+		return nil, fmt.Errorf("fix %w", err)
+	}
+	edits = append(edits, lowered...)
+
+	fixed := *v.Fix
+	fixed.Edits = edits
+	fixed.Ops = nil
+	return &fixed, nil
+}
+
+// resolveEdits resolves the text edits of the fix of v against the files of
+// the package.
+func (p *Package) resolveEdits(v model.Violation) ([]model.FixEdit, error) {
+	var edits []model.FixEdit
+	for _, e := range v.Fix.Edits {
+		name := cmp.Or(e.Position.File, v.Position.File)
+		file := p.file(name)
+		if file == nil {
+			return nil, fmt.Errorf("edit targets unknown file %q", name)
+		}
+		if err := p.load([]*ast.File{file}); err != nil {
+			return nil, err
+		}
+
+		path := p.Fset.File(file.Pos()).Name()
+		p.mu.Lock()
+		src := p.sources[path]
+		p.mu.Unlock()
+
+		resolved, err := Resolve(src, e)
+		if err != nil {
+			return nil, fmt.Errorf("invalid edit in %s: %w", filepath.Base(path), err)
+		}
+		resolved.Position.File = filepath.Base(path)
+		resolved.End.File = resolved.Position.File
+		edits = append(edits, resolved)
+	}
+	return edits, nil
+}
+
+// lowerOps turns the fix operations of v into text edits. Operations target
+// the file named by their position, or the violation's file.
+func lowerOps(pkg *Package, v model.Violation) ([]model.FixEdit, error) {
+	var edits []model.FixEdit
+	for _, op := range v.Fix.Ops {
+		name := op.Position.File
+		if name == "" {
+			name = v.Position.File
+		}
+		file := pkg.file(name)
+		if file == nil {
+			return nil, fmt.Errorf("%s: unknown file %q", op.Op, name)
+		}
+
+		files := []*ast.File{file}
+		if op.Op == "rename_identifier" {
+			files = pkg.Files
+		}
+		if err := pkg.load(files); err != nil {
+			return nil, err
+		}
+
+		lowered, err := pkg.lower(file, op)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op.Op, err)
+		}
+		edits = append(edits, lowered...)
+	}
+
+	return edits, nil
+}
+
+func (p *Package) lower(file *ast.File, op model.FixOp) ([]model.FixEdit, error) {
+	switch op.Op {
+	case "add_import":
+		return p.addImport(file, op)
+	case "remove_import":
+		return p.removeImport(file, op)
+	case "rename_identifier":
+		return p.renameIdentifier(file, op)
+	case "insert_doc_comment":
+		return p.insertDocComment(file, op)
+	case "add_struct_tag":
+		return p.addStructTag(file, op)
+	case "replace_call":
+		return p.replaceCall(file, op)
+	default:
+		return nil, errors.New("unknown operation")
+	}
+}
+
+func (p *Package) addImport(file *ast.File, op model.FixOp) ([]model.FixEdit, error) {
+	if op.Path == "" {
+		return nil, errors.New("path is required")
+	}
+	for _, imp := range file.Imports {
+		if importPath(imp) == op.Path && importName(imp) == op.Name {
+			return nil, nil
+		}
+	}
+
+	spec := strconv.Quote(op.Path)
+	if op.Name != "" {
+		spec = op.Name + " " + spec
+	}
+
+	var last *ast.GenDecl
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			last = gen
+		}
+	}
+
+	switch {
+	case last == nil:
+		return []model.FixEdit{p.edit(file.Name.End(), file.Name.End(), "\n\nimport "+spec)}, nil
+	case !last.Lparen.IsValid():
+		return []model.FixEdit{p.edit(last.End(), last.End(), "\nimport "+spec)}, nil
+	case p.startsLine(last.Rparen):
+		start := p.lineStart(last.Rparen)
+		return []model.FixEdit{p.edit(start, start, "\t"+spec+"\n")}, nil
+	default:
+		return []model.FixEdit{p.edit(last.Rparen, last.Rparen, "\n\t"+spec+"\n")}, nil
+	}
+}
+
+func (p *Package) removeImport(file *ast.File, op model.FixOp) ([]model.FixEdit, error) {
+	if op.Path == "" {
+		return nil, errors.New("path is required")
+	}
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			imp := spec.(*ast.ImportSpec)
+			if importPath(imp) != op.Path || (op.Name != "" && importName(imp) != op.Name) {
+				continue
+			}
+
+			if len(gen.Specs) == 1 {
+				return []model.FixEdit{p.deleteLines(withDoc(gen.Doc, gen.Pos()), gen.End())}, nil
+			}
+			start := withDoc(imp.Doc, imp.Pos())
+			if p.startsLine(start) && p.endsLine(imp.End()) {
+				return []model.FixEdit{p.deleteLines(start, imp.End())}, nil
+			}
+			return []model.FixEdit{p.edit(imp.Pos(), imp.End(), "")}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// insertDocComment adds a comment above the function, type, value or field
+// declared at Position, indented to match it.
+func (p *Package) insertDocComment(file *ast.File, op model.FixOp) ([]model.FixEdit, error) {
+	if op.Text == "" {
+		return nil, errors.New("text is required")
+	}
+
+	pos, err := p.pos(file, op.Position)
+	if err != nil {
+		return nil, err
+	}
+
+	path, _ := astutil.PathEnclosingInterval(file, pos, pos)
+	doc, found := declDoc(path, pos)
+	if !found {
+		return nil, errors.New("no declaration at position")
+	}
+	if doc != nil {
+		return nil, errors.New("declaration already has a doc comment")
+	}
+
+	start := p.lineStart(pos)
+	indent := p.source(start, pos)
+	indent = indent[:len(indent)-len(strings.TrimLeft(indent, " \t"))]
+
+	var text strings.Builder
+	for line := range strings.Lines(op.Text) {
+		text.WriteString(indent + strings.TrimRight("// "+strings.TrimSuffix(line, "\n"), " ") + "\n")
+	}
+	return []model.FixEdit{p.edit(start, start, text.String())}, nil
+}
+
+// declDoc finds the function, type, value or field declared at pos in path
+// and returns the comment that documents it.
+func declDoc(path []ast.Node, pos token.Pos) (*ast.CommentGroup, bool) {
+	for i, node := range path {
+		if node.Pos() != pos {
+			continue
+		}
+
+		var doc *ast.CommentGroup
+		switch n := node.(type) {
+		case *ast.FuncDecl:
+			return n.Doc, true
+		case *ast.Field:
+			return n.Doc, true
+		case *ast.TypeSpec:
+			doc = n.Doc
+		case *ast.ValueSpec:
+			doc = n.Doc
+		default:
+			continue
+		}
+
+		// A declaration without parentheses is documented by its GenDecl.
+		if i+1 < len(path) {
+			if gen, ok := path[i+1].(*ast.GenDecl); ok && !gen.Lparen.IsValid() {
+				doc = gen.Doc
+			}
+		}
+		return doc, true
+	}
+	return nil, false
+}
+
+// addStructTag adds Key with Value to the tag of the field at Position.
+func (p *Package) addStructTag(file *ast.File, op model.FixOp) ([]model.FixEdit, error) {
+	if op.Key == "" {
+		return nil, errors.New("key is required")
+	}
+
+	field, err := nodeAt[*ast.Field](p, file, op.Position)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := op.Key + ":" + strconv.Quote(op.Value)
+	if strings.Contains(entry, "`") {
+		return nil, errors.New("tag value cannot contain a backquote")
+	}
+
+	if field.Tag == nil {
+		return []model.FixEdit{p.edit(field.Type.End(), field.Type.End(), " `"+entry+"`")}, nil
+	}
+	if !strings.HasPrefix(field.Tag.Value, "`") {
+		return nil, errors.New("only raw string tags can be extended")
+	}
+
+	tag := strings.Trim(field.Tag.Value, "`")
+	if value, ok := reflect.StructTag(tag).Lookup(op.Key); ok {
+		if value == op.Value {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("field already has a %s tag", op.Key)
+	}
+	if tag != "" {
+		entry = " " + entry
+	}
+	end := field.Tag.End() - 1
+	return []model.FixEdit{p.edit(end, end, entry)}, nil
+}
+
+// replaceCall replaces the function and/or the arguments of the call at
+// Position.
+func (p *Package) replaceCall(file *ast.File, op model.FixOp) ([]model.FixEdit, error) {
+	if op.Function == "" && op.Args == nil {
+		return nil, errors.New("function or args is required")
+	}
+
+	call, err := nodeAt[*ast.CallExpr](p, file, op.Position)
+	if err != nil {
+		return nil, err
+	}
+
+	var edits []model.FixEdit
+	if op.Function != "" {
+		edits = append(edits, p.edit(call.Fun.Pos(), call.Fun.End(), op.Function))
+	}
+	if op.Args != nil {
+		edits = append(edits, p.edit(call.Lparen+1, call.Rparen, strings.Join(op.Args, ", ")))
+	}
+	return edits, nil
+}
+
+// nodeAt finds the innermost node of type T that starts at pos.
+func nodeAt[T ast.Node](p *Package, file *ast.File, pos model.Position) (T, error) {
+	var zero T
+
+	start, err := p.pos(file, pos)
+	if err != nil {
+		return zero, err
+	}
+
+	path, _ := astutil.PathEnclosingInterval(file, start, start)
+	for _, node := range path {
+		if n, ok := node.(T); ok && n.Pos() == start {
+			return n, nil
+		}
+	}
+	return zero, fmt.Errorf("no %s at %d:%d", strings.TrimPrefix(reflect.TypeFor[T]().String(), "*ast."), pos.Line, pos.Column)
+}
+
+// identAt finds the first identifier called name at or after pos on its line.
+func (p *Package) identAt(file *ast.File, name string, pos model.Position) *ast.Ident {
+	var found *ast.Ident
+	ast.Inspect(file, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok || found != nil || ident.Name != name {
+			return found == nil
+		}
+		if at := p.Fset.Position(ident.Pos()); at.Line == pos.Line && at.Column >= pos.Column {
+			found = ident
+		}
+		return true
+	})
+	return found
+}
+
+func (p *Package) file(name string) *ast.File {
+	for _, f := range p.Files {
+		if filepath.Base(p.Fset.File(f.Pos()).Name()) == filepath.Base(name) {
+			return f
+		}
+	}
+	return nil
+}
+
+// pos converts a line and column in file to a token.Pos.
+func (p *Package) pos(file *ast.File, pos model.Position) (token.Pos, error) {
+	tf := p.Fset.File(file.Pos())
+	if pos.Line < 1 || pos.Line > tf.LineCount() || pos.Column < 1 {
+		return token.NoPos, fmt.Errorf("position %d:%d is outside the file", pos.Line, pos.Column)
+	}
+	offset := tf.Offset(tf.LineStart(pos.Line)) + pos.Column - 1
+	if offset > tf.Size() {
+		return token.NoPos, fmt.Errorf("position %d:%d is outside the file", pos.Line, pos.Column)
+	}
+	return tf.Pos(offset), nil
+}
+
+func (p *Package) lineStart(pos token.Pos) token.Pos {
+	return p.Fset.File(pos).LineStart(p.Fset.Position(pos).Line)
+}
+
+// startsLine reports whether only whitespace precedes pos on its line.
+func (p *Package) startsLine(pos token.Pos) bool {
+	return strings.TrimSpace(p.source(p.lineStart(pos), pos)) == ""
+}
+
+// endsLine reports whether only whitespace or a comment follows pos on its line.
+func (p *Package) endsLine(pos token.Pos) bool {
+	tf := p.Fset.File(pos)
+	line := p.Fset.Position(pos).Line
+	if line == tf.LineCount() {
+		return true
+	}
+	rest := strings.TrimSpace(p.source(pos, tf.LineStart(line+1)))
+	return rest == "" || strings.HasPrefix(rest, "//")
+}
+
+// deleteLines deletes the full lines from the one containing start to the one
+// containing end.
+func (p *Package) deleteLines(start, end token.Pos) model.FixEdit {
+	tf := p.Fset.File(start)
+	from := p.lineStart(start)
+	to := tf.Pos(tf.Size())
+	if line := p.Fset.Position(end).Line; line < tf.LineCount() {
+		to = tf.LineStart(line + 1)
+	}
+	return p.edit(from, to, "")
+}
+
+// load reads the source of files that have not been read yet.
+func (p *Package) load(files []*ast.File) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.sources == nil {
+		p.sources = make(map[string][]byte)
+	}
+	readFile := p.ReadFile
+	if readFile == nil {
+		readFile = os.ReadFile
+	}
+
+	for _, f := range files {
+		name := p.Fset.File(f.Pos()).Name()
+		if _, ok := p.sources[name]; ok {
+			continue
+		}
+		src, err := readFile(filepath.Clean(name))
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", name, err)
+		}
+		p.sources[name] = src
+	}
+	return nil
+}
+
+// source returns the text between two positions of a loaded file.
+func (p *Package) source(from, to token.Pos) string {
+	tf := p.Fset.File(from)
+
+	p.mu.Lock()
+	src := p.sources[tf.Name()]
+	p.mu.Unlock()
+
+	return string(src[tf.Offset(from):tf.Offset(to)])
+}
+
+// edit builds a resolved FixEdit replacing [from, to) with text.
+func (p *Package) edit(from, to token.Pos, text string) model.FixEdit {
+	start, end := p.Fset.Position(from), p.Fset.Position(to)
+	file := filepath.Base(start.Filename)
+
+	return model.FixEdit{
+		Position:  model.Position{File: file, Line: start.Line, Column: start.Column},
+		End:       &model.Position{File: file, Line: end.Line, Column: end.Column},
+		Offset:    &start.Offset,
+		EndOffset: &end.Offset,
+		OldText:   p.source(from, to),
+		NewText:   text,
+	}
+}
+
+func importPath(imp *ast.ImportSpec) string {
+	path, _ := strconv.Unquote(imp.Path.Value)
+	return path
+}
+
+func importName(imp *ast.ImportSpec) string {
+	if imp.Name == nil {
+		return ""
+	}
+	return imp.Name.Name
+}
+
+func withDoc(doc *ast.CommentGroup, pos token.Pos) token.Pos {
+	if doc != nil {
+		return doc.Pos()
+	}
+	return pos
+}
@@ -0,0 +1,376 @@
+package fix
+
+import (
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/burdzwastaken/regolint/internal/model"
+)
+
+// lowerAndApply lowers ops reported against p.go and returns the formatted
+// source of every file after applying the resulting edits.
+func lowerAndApply(t *testing.T, sources map[string]string, ops ...model.FixOp) (map[string]string, error) {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range slices.Sorted(maps.Keys(sources)) {
+		f, err := parser.ParseFile(fset, name, sources[name], parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+
+	// Imports are left unresolved; only rename_identifier needs type
+	// information, and its tests do not import anything.
+	info := &types.Info{Defs: map[*ast.Ident]types.Object{}, Uses: map[*ast.Ident]types.Object{}}
+	conf := &types.Config{Error: func(error) {}}
+	pkg, _ := conf.Check("p", fset, files, info)
+
+	fixPkg := &Package{
+		Fset:      fset,
+		Files:     files,
+		Types:     pkg,
+		TypesInfo: info,
+		ReadFile: func(name string) ([]byte, error) {
+			if src, ok := sources[name]; ok {
+				return []byte(src), nil
+			}
+			return nil, os.ErrNotExist
+		},
+	}
+
+	violations := []model.Violation{{
+		Rule:     "TEST001",
+		Position: model.Position{File: "p.go", Line: 1, Column: 1},
+		Fix:      &model.Fix{Ops: ops},
+	}}
+	fixed, err := fixPkg.lowerFix(violations[0])
+	if err != nil {
+		return nil, err
+	}
+	if fixed.Ops != nil {
+		t.Errorf("Ops = %v, want lowered", fixed.Ops)
+	}
+
+	byFile := make(map[string][]edit)
+	for _, e := range fixed.Edits {
+		byFile[e.Position.File] = append(byFile[e.Position.File], edit{start: *e.Offset, end: *e.EndOffset, newText: e.NewText})
+	}
+
+	result := make(map[string]string)
+	for name, src := range sources {
+		out, err := format.Source(splice([]byte(src), byFile[name]))
+		if err != nil {
+			t.Fatalf("formatting %s: %v", name, err)
+		}
+		result[name] = string(out)
+	}
+	return result, nil
+}
+
+func TestLowerOps(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		op      model.FixOp
+		want    string
+		wantErr string
+	}{
+		{
+			name: "add import to block",
+			src:  "package p\n\nimport (\n\t\"os\"\n)\n\nvar _ = os.Args\n",
+			op:   model.FixOp{Op: "add_import", Path: "errors"},
+			want: "package p\n\nimport (\n\t\"errors\"\n\t\"os\"\n)\n\nvar _ = os.Args\n",
+		},
+		{
+			name: "add named import without imports",
+			src:  "package p\n",
+			op:   model.FixOp{Op: "add_import", Path: "errors", Name: "errs"},
+			want: "package p\n\nimport errs \"errors\"\n",
+		},
+		{
+			name: "add existing import is a no-op",
+			src:  "package p\n\nimport \"os\"\n\nvar _ = os.Args\n",
+			op:   model.FixOp{Op: "add_import", Path: "os"},
+			want: "package p\n\nimport \"os\"\n\nvar _ = os.Args\n",
+		},
+		{
+			name: "remove import from block",
+			src:  "package p\n\nimport (\n\t\"errors\"\n\t\"os\"\n)\n\nvar _ = os.Args\n",
+			op:   model.FixOp{Op: "remove_import", Path: "errors"},
+			want: "package p\n\nimport (\n\t\"os\"\n)\n\nvar _ = os.Args\n",
+		},
+		{
+			name: "remove only import",
+			src:  "package p\n\n// errors is unused.\nimport \"errors\"\n\nvar x = 1\n",
+			op:   model.FixOp{Op: "remove_import", Path: "errors"},
+			want: "package p\n\nvar x = 1\n",
+		},
+		{
+			name: "rename package-level function",
+			src:  "package p\n\nfunc old() int { return 1 }\n\nvar x = old() + old()\n",
+			op:   model.FixOp{Op: "rename_identifier", Name: "old", NewName: "renamed"},
+			want: "package p\n\nfunc renamed() int { return 1 }\n\nvar x = renamed() + renamed()\n",
+		},
+		{
+			name: "rename local by position leaves other objects alone",
+			src:  "package p\n\nvar v = 1\n\nfunc f() int {\n\tv := 2\n\treturn v\n}\n",
+			op: model.FixOp{
+				Op: "rename_identifier", Name: "v", NewName: "local",
+				Position: model.Position{Line: 6, Column: 1},
+			},
+			want: "package p\n\nvar v = 1\n\nfunc f() int {\n\tlocal := 2\n\treturn local\n}\n",
+		},
+		{
+			name:    "rename conflicts with existing name",
+			src:     "package p\n\nfunc a() {}\n\nfunc b() {}\n",
+			op:      model.FixOp{Op: "rename_identifier", Name: "a", NewName: "b"},
+			wantErr: "b is already declared",
+		},
+		{
+			name:    "rename shadowed at a use",
+			src:     "package p\n\nvar old = 1\n\nfunc f() int {\n\tx := 2\n\treturn old + x\n}\n",
+			op:      model.FixOp{Op: "rename_identifier", Name: "old", NewName: "x"},
+			wantErr: "would refer to the x declared in an enclosing scope",
+		},
+		{
+			name:    "rename captures a predeclared identifier",
+			src:     "package p\n\nfunc old() {}\n\nvar n = len(\"a\")\n",
+			op:      model.FixOp{Op: "rename_identifier", Name: "old", NewName: "len"},
+			wantErr: "len at p.go:5:9 would refer to the renamed old",
+		},
+		{
+			name: "rename local captures nothing after its scope",
+			src:  "package p\n\nvar x = 1\n\nfunc f() int {\n\tif y := 2; y > 0 {\n\t\treturn y\n\t}\n\treturn x\n}\n",
+			op: model.FixOp{
+				Op: "rename_identifier", Name: "y", NewName: "z",
+				Position: model.Position{Line: 6, Column: 1},
+			},
+			want: "package p\n\nvar x = 1\n\nfunc f() int {\n\tif z := 2; z > 0 {\n\t\treturn z\n\t}\n\treturn x\n}\n",
+		},
+		{
+			name: "rename method to an existing field",
+			src:  "package p\n\ntype T struct{ A int }\n\nfunc (T) M() {}\n",
+			op: model.FixOp{
+				Op: "rename_identifier", Name: "M", NewName: "A",
+				Position: model.Position{Line: 5, Column: 1},
+			},
+			wantErr: "p.T already has a field or method A",
+		},
+		{
+			name: "rename field",
+			src:  "package p\n\ntype T struct{ A int }\n\nvar v = T{A: 1}.A\n",
+			op: model.FixOp{
+				Op: "rename_identifier", Name: "A", NewName: "B",
+				Position: model.Position{Line: 3, Column: 1},
+			},
+			want: "package p\n\ntype T struct{ B int }\n\nvar v = T{B: 1}.B\n",
+		},
+		{
+			name: "insert doc comment on method",
+			src:  "package p\n\ntype T struct{}\n\nfunc (T) M() {}\n",
+			op: model.FixOp{
+				Op: "insert_doc_comment", Text: "M does things.\n\nIt is a method.",
+				Position: model.Position{Line: 5, Column: 1},
+			},
+			want: "package p\n\ntype T struct{}\n\n// M does things.\n//\n// It is a method.\nfunc (T) M() {}\n",
+		},
+		{
+			name: "insert doc comment on grouped type",
+			src:  "package p\n\ntype (\n\tA int\n)\n",
+			op: model.FixOp{
+				Op: "insert_doc_comment", Text: "A is a number.",
+				Position: model.Position{Line: 4, Column: 2},
+			},
+			want: "package p\n\ntype (\n\t// A is a number.\n\tA int\n)\n",
+		},
+		{
+			name: "insert doc comment when one exists",
+			src:  "package p\n\n// T exists.\ntype T int\n",
+			op: model.FixOp{
+				Op: "insert_doc_comment", Text: "T again.",
+				Position: model.Position{Line: 4, Column: 6},
+			},
+			wantErr: "already has a doc comment",
+		},
+		{
+			name: "add struct tag to untagged field",
+			src:  "package p\n\ntype T struct {\n\tName string\n}\n",
+			op: model.FixOp{
+				Op: "add_struct_tag", Key: "json", Value: "name",
+				Position: model.Position{Line: 4, Column: 2},
+			},
+			want: "package p\n\ntype T struct {\n\tName string `json:\"name\"`\n}\n",
+		},
+		{
+			name: "add struct tag to existing tag",
+			src:  "package p\n\ntype T struct {\n\tName string `yaml:\"name\"`\n}\n",
+			op: model.FixOp{
+				Op: "add_struct_tag", Key: "json", Value: "name,omitempty",
+				Position: model.Position{Line: 4, Column: 2},
+			},
+			want: "package p\n\ntype T struct {\n\tName string `yaml:\"name\" json:\"name,omitempty\"`\n}\n",
+		},
+		{
+			name: "add conflicting struct tag",
+			src:  "package p\n\ntype T struct {\n\tName string `json:\"n\"`\n}\n",
+			op: model.FixOp{
+				Op: "add_struct_tag", Key: "json", Value: "name",
+				Position: model.Position{Line: 4, Column: 2},
+			},
+			wantErr: "already has a json tag",
+		},
+		{
+			name: "replace call function and args",
+			src:  "package p\n\nfunc f(a, b int) int { return a }\n\nfunc g(a int) int { return a }\n\nvar x = f(1, 2)\n",
+			op: model.FixOp{
+				Op: "replace_call", Function: "g", Args: []string{"2"},
+				Position: model.Position{Line: 7, Column: 9},
+			},
+			want: "package p\n\nfunc f(a, b int) int { return a }\n\nfunc g(a int) int { return a }\n\nvar x = g(2)\n",
+		},
+		{
+			name:    "replace call without a call",
+			src:     "package p\n\nvar x = 1\n",
+			op:      model.FixOp{Op: "replace_call", Function: "g", Position: model.Position{Line: 3, Column: 9}},
+			wantErr: "no CallExpr at 3:9",
+		},
+		{
+			name:    "unknown operation",
+			src:     "package p\n",
+			op:      model.FixOp{Op: "explode"},
+			wantErr: "fix explode: unknown operation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lowerAndApply(t, map[string]string{"p.go": tt.src}, tt.op)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Lower() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Lower() error = %v", err)
+			}
+			if got["p.go"] != tt.want {
+				t.Errorf("result =\n%s\nwant\n%s", got["p.go"], tt.want)
+			}
+		})
+	}
+}
+
+func TestLowerOpsRenameAcrossFiles(t *testing.T) {
+	got, err := lowerAndApply(t, map[string]string{
+		"p.go": "package p\n\ntype Old struct{}\n",
+		"q.go": "package p\n\nfunc New() *Old { return &Old{} }\n",
+	}, model.FixOp{Op: "rename_identifier", Name: "Old", NewName: "Thing"})
+	if err != nil {
+		t.Fatalf("Lower() error = %v", err)
+	}
+
+	if want := "package p\n\ntype Thing struct{}\n"; got["p.go"] != want {
+		t.Errorf("p.go =\n%s\nwant\n%s", got["p.go"], want)
+	}
+	if want := "package p\n\nfunc New() *Thing { return &Thing{} }\n"; got["q.go"] != want {
+		t.Errorf("q.go =\n%s\nwant\n%s", got["q.go"], want)
+	}
+}
+
+func TestLowerResolvesEdits(t *testing.T) {
+	src := "package p\n\nfunc f() {\n\tx := 1\n}\n"
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "/src/fix.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixPkg := &Package{
+		Fset:  fset,
+		Files: []*ast.File{file},
+		ReadFile: func(name string) ([]byte, error) {
+			if name == "/src/fix.go" {
+				return []byte(src), nil
+			}
+			return nil, os.ErrNotExist
+		},
+	}
+
+	body := model.FixEdit{
+		Position: model.Position{File: "fix.go", Line: 3, Column: 10},
+		End:      &model.Position{Line: 5, Column: 2},
+		NewText:  "{}",
+	}
+	offset, endOffset := 0, 7
+	header := model.FixEdit{Offset: &offset, EndOffset: &endOffset, NewText: "package"}
+	outside := model.FixEdit{Position: model.Position{Line: 3, Column: 10}, End: &model.Position{Line: 9, Column: 1}}
+
+	violations := []model.Violation{
+		{Rule: "FIX001", Fix: &model.Fix{Edits: []model.FixEdit{body, header}}},
+		{Rule: "FIX002", Fix: &model.Fix{Edits: []model.FixEdit{outside}}},
+		{Rule: "FIX003", Position: model.Position{File: "gone.go"}, Fix: &model.Fix{Edits: []model.FixEdit{header}}},
+	}
+	fixPkg.Lower(violations, "/src/fix.go")
+
+	edits := violations[0].Fix.Edits
+	if len(edits) != 2 {
+		t.Fatalf("expected 2 resolved edits, got %+v", edits)
+	}
+	if edits[0].OldText != "{\n\tx := 1\n}" || *edits[0].Offset != 20 || *edits[0].EndOffset != 31 {
+		t.Errorf("body edit = %q [%d:%d], want range of the function body", edits[0].OldText, *edits[0].Offset, *edits[0].EndOffset)
+	}
+	if edits[1].Position.File != "fix.go" || edits[1].Position.Line != 1 || edits[1].End.Column != 8 {
+		t.Errorf("header edit = %+v to %+v, want fix.go:1:1 to 1:8", edits[1].Position, *edits[1].End)
+	}
+
+	for _, v := range violations[1:] {
+		if v.Fix != nil {
+			t.Errorf("%s: expected the invalid fix to be dropped, got %+v", v.Rule, v.Fix)
+		}
+	}
+}
+
+func TestLowerDropsInvalidOps(t *testing.T) {
+	src := "package p\n\n// T exists.\ntype T struct {\n\tName string \"yaml:\\\"name\\\"\"\n\tAge  int\n}\n"
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixPkg := &Package{
+		Fset:     fset,
+		Files:    []*ast.File{file},
+		ReadFile: func(string) ([]byte, error) { return []byte(src), nil },
+	}
+
+	violations := []model.Violation{
+		{Rule: "TAG001", Fix: &model.Fix{Ops: []model.FixOp{
+			{Op: "add_struct_tag", Key: "json", Value: "name", Position: model.Position{Line: 5, Column: 2}},
+		}}},
+		{Rule: "PKG001", Fix: &model.Fix{Ops: []model.FixOp{
+			{Op: "insert_doc_comment", Text: "T again.", Position: model.Position{Line: 4, Column: 6}},
+		}}},
+		{Rule: "TAG001", Fix: &model.Fix{Ops: []model.FixOp{
+			{Op: "add_struct_tag", Key: "json", Value: "age", Position: model.Position{Line: 6, Column: 2}},
+		}}},
+	}
+	fixPkg.Lower(violations, "p.go")
+
+	if violations[0].Fix != nil || violations[1].Fix != nil {
+		t.Errorf("expected fixes that cannot be lowered to be dropped, got %+v, %+v", violations[0].Fix, violations[1].Fix)
+	}
+	if f := violations[2].Fix; f == nil || len(f.Edits) != 1 || f.Edits[0].NewText != " `json:\"age\"`" {
+		t.Errorf("expected the valid fix to be lowered, got %+v", f)
+	}
+}
@@ -0,0 +1,175 @@
+package fix
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/burdzwastaken/regolint/internal/model"
+)
+
+// renameIdentifier renames the object declared or used by the first
+// identifier called Name at or after Position on its line, or the
+// package-level object called Name. Every reference within the package is
+// renamed; references from other packages are not. A rename that would change
+// what any identifier in the package refers to is refused.
+func (p *Package) renameIdentifier(file *ast.File, op model.FixOp) ([]model.FixEdit, error) {
+	if op.Name == "" || !token.IsIdentifier(op.NewName) {
+		return nil, errors.New("name and a valid new_name are required")
+	}
+	if p.TypesInfo == nil || p.Types == nil {
+		return nil, errors.New("type information is unavailable")
+	}
+
+	var obj types.Object
+	if op.Position.Line > 0 {
+		if ident := p.identAt(file, op.Name, op.Position); ident != nil {
+			obj = p.TypesInfo.ObjectOf(ident)
+		}
+	} else {
+		obj = p.Types.Scope().Lookup(op.Name)
+	}
+	if obj == nil {
+		return nil, fmt.Errorf("no object named %s", op.Name)
+	}
+	if err := p.renameConflict(obj, op.NewName); err != nil {
+		return nil, err
+	}
+
+	var edits []model.FixEdit
+	for _, f := range p.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			ident, ok := n.(*ast.Ident)
+			if ok && ident.Name == op.Name && (p.TypesInfo.Defs[ident] == obj || p.TypesInfo.Uses[ident] == obj) {
+				edits = append(edits, p.edit(ident.Pos(), ident.End(), op.NewName))
+			}
+			return true
+		})
+	}
+	return edits, nil
+}
+
+// renameConflict reports why renaming obj to name would change the meaning of
+// the package: name is already declared alongside obj, a reference to obj
+// would resolve to another object called name, or a reference to another
+// object called name would resolve to obj.
+func (p *Package) renameConflict(obj types.Object, name string) error {
+	scope := obj.Parent()
+	if scope == nil {
+		return p.memberConflict(obj, name)
+	}
+	if scope.Lookup(name) != nil {
+		return fmt.Errorf("%s is already declared in the scope of %s", name, obj.Name())
+	}
+
+	var err error
+	for _, f := range p.Files {
+		lexicalIdents(f, func(ident *ast.Ident) {
+			if err != nil {
+				return
+			}
+			switch used := p.TypesInfo.Uses[ident]; {
+			case used == obj:
+				if _, other := p.innermost(ident.Pos()).LookupParent(name, ident.Pos()); other != nil {
+					err = fmt.Errorf("%s at %s would refer to the %s declared in an enclosing scope", obj.Name(), p.Fset.Position(ident.Pos()), name)
+				}
+			case used != nil && ident.Name == name && p.captures(obj, used, ident.Pos()):
+				err = fmt.Errorf("%s at %s would refer to the renamed %s", name, p.Fset.Position(ident.Pos()), obj.Name())
+			}
+		})
+	}
+	return err
+}
+
+// captures reports whether an identifier at pos that refers to used would
+// refer to obj instead if obj had the same name, because obj is declared in a
+// scope between the identifier and used.
+func (p *Package) captures(obj, used types.Object, pos token.Pos) bool {
+	if used.Parent() == nil || (used.Pkg() != nil && used.Pkg() != p.Types) {
+		return false
+	}
+	for s := p.innermost(pos); s != nil && s != used.Parent(); s = s.Parent() {
+		if s == obj.Parent() {
+			// Local objects are only in scope after their declaration.
+			return s == p.Types.Scope() || obj.Pos() < pos
+		}
+	}
+	return false
+}
+
+// memberConflict reports whether a field or method called name already
+// exists on the type declaring the field or method obj.
+func (p *Package) memberConflict(obj types.Object, name string) error {
+	var owner types.Type
+	switch obj := obj.(type) {
+	case *types.Func:
+		if recv := obj.Type().(*types.Signature).Recv(); recv != nil {
+			owner = recv.Type()
+		}
+	case *types.Var:
+		if obj.IsField() {
+			owner = p.fieldOwner(obj)
+		}
+	}
+	if owner == nil {
+		return fmt.Errorf("cannot find the type declaring %s", obj.Name())
+	}
+
+	if other, _, _ := types.LookupFieldOrMethod(owner, true, p.Types, name); other != nil {
+		return fmt.Errorf("%s already has a field or method %s", owner, name)
+	}
+	return nil
+}
+
+// fieldOwner returns the type declared in the package whose struct has field.
+func (p *Package) fieldOwner(field *types.Var) types.Type {
+	for _, obj := range p.TypesInfo.Defs {
+		if tn, ok := obj.(*types.TypeName); ok && hasField(tn.Type().Underlying(), field) {
+			return tn.Type()
+		}
+	}
+	for _, tv := range p.TypesInfo.Types {
+		if hasField(tv.Type, field) {
+			return tv.Type
+		}
+	}
+	return nil
+}
+
+func hasField(t types.Type, field *types.Var) bool {
+	st, ok := t.(*types.Struct)
+	if !ok {
+		return false
+	}
+	for f := range st.Fields() {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// innermost returns the innermost scope of the package containing pos.
+func (p *Package) innermost(pos token.Pos) *types.Scope {
+	if s := p.Types.Scope().Innermost(pos); s != nil {
+		return s
+	}
+	return p.Types.Scope()
+}
+
+// lexicalIdents calls fn for every identifier in node that is resolved
+// through scopes, leaving out selected fields, methods and package members.
+func lexicalIdents(node ast.Node, fn func(*ast.Ident)) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			lexicalIdents(n.X, fn)
+			return false
+		case *ast.Ident:
+			fn(n)
+		}
+		return true
+	})
+}
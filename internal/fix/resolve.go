@@ -0,0 +1,121 @@
+package fix
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/burdzwastaken/regolint/internal/model"
+)
+
+// Resolve checks that the range of e exists in src and returns the edit with
+// both forms of its range filled in: Position and End as 1-based line and
+// byte column, and Offset and EndOffset as byte offsets. OldText is set to
+// the text the range covers; if e already had OldText it must match.
+func Resolve(src []byte, e model.FixEdit) (model.FixEdit, error) {
+	lines := lineOffsets(src)
+
+	start, err := resolvePoint(src, lines, e.Offset, &e.Position, "position")
+	if err != nil {
+		return e, err
+	}
+
+	var end int
+	switch {
+	case e.EndOffset != nil || e.End != nil:
+		end, err = resolvePoint(src, lines, e.EndOffset, e.End, "end")
+		if err != nil {
+			return e, err
+		}
+		if end < start {
+			return e, fmt.Errorf("end %s precedes start %s", pointString(lines, end), pointString(lines, start))
+		}
+	default:
+		end = start + len(e.OldText)
+		if end > len(src) {
+			return e, fmt.Errorf("old_text at %s runs past the end of the file", pointString(lines, start))
+		}
+	}
+
+	if e.OldText != "" && string(src[start:end]) != e.OldText {
+		return e, fmt.Errorf("old_text does not match %s", pointString(lines, start))
+	}
+
+	line, column := lineColumn(lines, start)
+	endLine, endColumn := lineColumn(lines, end)
+
+	e.Position = model.Position{File: e.Position.File, Line: line, Column: column}
+	e.End = &model.Position{File: e.Position.File, Line: endLine, Column: endColumn}
+	e.Offset = &start
+	e.EndOffset = &end
+	e.OldText = string(src[start:end])
+	return e, nil
+}
+
+// resolvePoint converts one end of a range to a byte offset. When both an
+// offset and a line are given they must agree.
+func resolvePoint(src []byte, lines []int, offset *int, pos *model.Position, name string) (int, error) {
+	hasLine := pos != nil && pos.Line > 0
+
+	if offset != nil {
+		if *offset < 0 || *offset > len(src) {
+			return 0, fmt.Errorf("%s offset %d is outside the file", name, *offset)
+		}
+		if hasLine {
+			if off, ok := lineOffset(src, lines, pos.Line, pos.Column); !ok || off != *offset {
+				return 0, fmt.Errorf("%s %d:%d does not match offset %d", name, pos.Line, pos.Column, *offset)
+			}
+		}
+		return *offset, nil
+	}
+
+	if !hasLine {
+		return 0, errors.New(name + " has neither a line nor an offset")
+	}
+	off, ok := lineOffset(src, lines, pos.Line, pos.Column)
+	if !ok {
+		return 0, fmt.Errorf("%s %d:%d is outside the file", name, pos.Line, pos.Column)
+	}
+	return off, nil
+}
+
+// lineOffsets returns the byte offset at which each line of src starts.
+func lineOffsets(src []byte) []int {
+	lines := []int{0}
+	for i, b := range src {
+		if b == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	return lines
+}
+
+// lineOffset converts a 1-based line and byte column to a byte offset. The
+// column may point just past the last character of the line.
+func lineOffset(src []byte, lines []int, line, column int) (int, bool) {
+	if line < 1 || line > len(lines) || column < 1 {
+		return 0, false
+	}
+
+	lineEnd := len(src)
+	if line < len(lines) {
+		lineEnd = lines[line] - 1
+	}
+
+	off := lines[line-1] + column - 1
+	if off > lineEnd {
+		return 0, false
+	}
+	return off, true
+}
+
+// lineColumn converts a byte offset to a 1-based line and byte column.
+func lineColumn(lines []int, offset int) (int, int) {
+	line := sort.Search(len(lines), func(i int) bool { return lines[i] > offset })
+	return line, offset - lines[line-1] + 1
+}
+
+func pointString(lines []int, offset int) string {
+	line, column := lineColumn(lines, offset)
+	return fmt.Sprintf("%d:%d", line, column)
+}
@@ -0,0 +1,119 @@
+package fix
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/burdzwastaken/regolint/internal/model"
+)
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestResolve(t *testing.T) {
+	src := []byte("package p\n\nfunc f() {\n\tx := 1\n}\n")
+
+	tests := []struct {
+		name    string
+		edit    model.FixEdit
+		want    string // OldText of the resolved range
+		wantEnd model.Position
+		wantErr string
+	}{
+		{
+			name:    "old text",
+			edit:    model.FixEdit{Position: model.Position{Line: 3, Column: 6}, OldText: "f"},
+			want:    "f",
+			wantEnd: model.Position{Line: 3, Column: 7},
+		},
+		{
+			name: "multi-line end position",
+			edit: model.FixEdit{
+				Position: model.Position{Line: 3, Column: 1},
+				End:      &model.Position{Line: 5, Column: 2},
+			},
+			want:    "func f() {\n\tx := 1\n}",
+			wantEnd: model.Position{Line: 5, Column: 2},
+		},
+		{
+			name:    "offsets",
+			edit:    model.FixEdit{Offset: intPtr(11), EndOffset: intPtr(15)},
+			want:    "func",
+			wantEnd: model.Position{Line: 3, Column: 5},
+		},
+		{
+			name:    "insertion at end of line",
+			edit:    model.FixEdit{Position: model.Position{Line: 1, Column: 10}, NewText: " // p"},
+			want:    "",
+			wantEnd: model.Position{Line: 1, Column: 10},
+		},
+		{
+			name: "old text checked against explicit range",
+			edit: model.FixEdit{
+				Position: model.Position{Line: 3, Column: 1},
+				End:      &model.Position{Line: 3, Column: 5},
+				OldText:  "fun",
+			},
+			wantErr: "old_text does not match 3:1",
+		},
+		{
+			name:    "column past end of line",
+			edit:    model.FixEdit{Position: model.Position{Line: 1, Column: 11}},
+			wantErr: "position 1:11 is outside the file",
+		},
+		{
+			name:    "line past end of file",
+			edit:    model.FixEdit{Position: model.Position{Line: 7, Column: 1}},
+			wantErr: "position 7:1 is outside the file",
+		},
+		{
+			name: "end before start",
+			edit: model.FixEdit{
+				Position: model.Position{Line: 3, Column: 5},
+				End:      &model.Position{Line: 3, Column: 1},
+			},
+			wantErr: "end 3:1 precedes start 3:5",
+		},
+		{
+			name:    "offset and position disagree",
+			edit:    model.FixEdit{Position: model.Position{Line: 3, Column: 1}, Offset: intPtr(12)},
+			wantErr: "position 3:1 does not match offset 12",
+		},
+		{
+			name:    "offset outside file",
+			edit:    model.FixEdit{Offset: intPtr(100)},
+			wantErr: "position offset 100 is outside the file",
+		},
+		{
+			name:    "missing start",
+			edit:    model.FixEdit{NewText: "x"},
+			wantErr: "position has neither a line nor an offset",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(src, tt.edit)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Resolve() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+
+			if got.OldText != tt.want {
+				t.Errorf("OldText = %q, want %q", got.OldText, tt.want)
+			}
+			if *got.End != tt.wantEnd {
+				t.Errorf("End = %+v, want %+v", *got.End, tt.wantEnd)
+			}
+			if text := string(src[*got.Offset:*got.EndOffset]); text != tt.want {
+				t.Errorf("offsets cover %q, want %q", text, tt.want)
+			}
+		})
+	}
+}
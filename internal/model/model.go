@@ -13,7 +13,41 @@ type CodeContext struct {
 	Calls       []CallInfo        `json:"calls"`
 	TypeUsages  []TypeUsageInfo   `json:"type_usages"`
 	FieldAccess []FieldAccessInfo `json:"field_accesses"`
+	Assignments []AssignmentInfo  `json:"assignments"`
+	TaintFlows  []TaintFlow       `json:"taint_flows,omitempty"`
 	Nolints     []NolintDirective `json:"nolints,omitempty"`
+
+	BuildConstraint *BuildConstraint `json:"build_constraint,omitempty"`
+	Directives      []Directive      `json:"directives,omitempty"`
+	Generated       *GeneratedMarker `json:"generated,omitempty"`
+}
+
+// BuildConstraint is the build constraint of a file, from its //go:build line
+// or, failing that, its // +build lines. Expression is the constraint in
+// //go:build syntax and Tags the build tags it mentions.
+type BuildConstraint struct {
+	Expression string   `json:"expression"`
+	Tags       []string `json:"tags"`
+	Position   Position `json:"position"`
+}
+
+// Directive is a //go: compiler or tool directive other than //go:build,
+// such as //go:generate or //go:linkname. Args holds its arguments with
+// quoted strings unquoted, and Target names the declaration whose doc
+// comment it appears in, if any.
+type Directive struct {
+	Name     string   `json:"name"`
+	Args     []string `json:"args"`
+	Target   string   `json:"target,omitempty"`
+	Position Position `json:"position"`
+}
+
+// GeneratedMarker is the "// Code generated ... DO NOT EDIT." comment marking
+// a file as generated. Generator is the text between "Code generated" and
+// "DO NOT EDIT.", without a leading "by" or trailing period.
+type GeneratedMarker struct {
+	Generator string   `json:"generator,omitempty"`
+	Position  Position `json:"position"`
 }
 
 // NolintDirective represents a nolint comment that suppresses violations.
@@ -36,6 +70,8 @@ type PackageInfo struct {
 	Name string `json:"name"`
 	Path string `json:"path"`
 	Doc  string `json:"doc,omitempty"`
+	// Position is the package clause of the file.
+	Position Position `json:"position,omitzero"`
 }
 
 // ImportInfo represents an import declaration.
@@ -47,76 +83,169 @@ type ImportInfo struct {
 
 // ParameterInfo represents a function parameter or return value.
 type ParameterInfo struct {
-	Name string `json:"name,omitempty"`
-	Type string `json:"type"`
+	Name     string      `json:"name,omitempty"`
+	Type     string      `json:"type"`
+	TypeInfo *TypeDetail `json:"type_info,omitempty"`
+}
+
+// TypeDetail describes a resolved type so that policies need not parse type
+// strings. Name, Package and Underlying describe the type left after
+// removing PointerDepth levels of pointers, such as net/http.Request for
+// **http.Request. Elem is the element type of a pointer, slice, array, map or
+// channel, and Key the key type of a map.
+type TypeDetail struct {
+	Kind         string      `json:"kind"`
+	Name         string      `json:"name,omitempty"`
+	Package      string      `json:"package,omitempty"`
+	Underlying   string      `json:"underlying"`
+	PointerDepth int         `json:"pointer_depth"`
+	Key          *TypeDetail `json:"key,omitempty"`
+	Elem         *TypeDetail `json:"elem,omitempty"`
+}
+
+// TypeParamInfo represents a type parameter of a generic function or type.
+type TypeParamInfo struct {
+	Name       string `json:"name"`
+	Constraint string `json:"constraint"`
 }
 
-// FunctionInfo represents a function or method declaration.
+// FunctionInfo represents a function or method declaration. QualifiedName
+// names it as callees are named and needs type information; Callees and
+// Callers list its direct neighbours in the static call graph, when one is
+// built.
 type FunctionInfo struct {
-	Name        string          `json:"name"`
-	Receiver    string          `json:"receiver,omitempty"`
-	Parameters  []ParameterInfo `json:"parameters"`
-	Returns     []ParameterInfo `json:"returns"`
-	IsExported  bool            `json:"is_exported"`
-	IsTest      bool            `json:"is_test"`
-	Complexity  int             `json:"complexity"`
-	LineCount   int             `json:"line_count"`
-	Position    Position        `json:"position"`
-	Comments    []string        `json:"comments,omitempty"`
-	Annotations map[string]any  `json:"annotations,omitempty"`
-}
-
-// FieldInfo represents a struct field.
+	Name          string          `json:"name"`
+	QualifiedName string          `json:"qualified_name,omitempty"`
+	Receiver      string          `json:"receiver,omitempty"`
+	TypeParams    []TypeParamInfo `json:"type_params,omitempty"`
+	Parameters    []ParameterInfo `json:"parameters"`
+	Returns       []ParameterInfo `json:"returns"`
+	IsExported    bool            `json:"is_exported"`
+	IsTest        bool            `json:"is_test"`
+	Complexity    int             `json:"complexity"`
+	LineCount     int             `json:"line_count"`
+	Position      Position        `json:"position"`
+	Comments      []string        `json:"comments,omitempty"`
+	Annotations   map[string]any  `json:"annotations,omitempty"`
+	Callees       []string        `json:"callees,omitempty"`
+	Callers       []string        `json:"callers,omitempty"`
+}
+
+// FieldInfo represents a struct field. TagMap holds the parsed Tags by key,
+// and TagErrors describes any malformation found while parsing them.
 type FieldInfo struct {
-	Name       string   `json:"name"`
-	Type       string   `json:"type"`
-	Tags       string   `json:"tags,omitempty"`
-	IsExported bool     `json:"is_exported"`
-	IsEmbedded bool     `json:"is_embedded"`
-	Position   Position `json:"position"`
+	Name       string              `json:"name"`
+	Type       string              `json:"type"`
+	TypeInfo   *TypeDetail         `json:"type_info,omitempty"`
+	Tags       string              `json:"tags,omitempty"`
+	TagMap     map[string]TagValue `json:"tag_map,omitempty"`
+	TagErrors  []string            `json:"tag_errors,omitempty"`
+	IsExported bool                `json:"is_exported"`
+	IsEmbedded bool                `json:"is_embedded"`
+	Position   Position            `json:"position"`
 }
 
-// MethodInfo represents a method signature in an interface or struct.
+// TagValue is the value of one key of a struct tag. Name and Options split it
+// at commas, as encoding/json and most other packages do.
+type TagValue struct {
+	Value   string   `json:"value"`
+	Name    string   `json:"name"`
+	Options []string `json:"options"`
+}
+
+// MethodInfo represents a method signature in an interface or a method
+// declared on a type.
 type MethodInfo struct {
-	Name       string          `json:"name"`
-	Parameters []ParameterInfo `json:"parameters"`
-	Returns    []ParameterInfo `json:"returns"`
-	IsExported bool            `json:"is_exported"`
+	Name            string          `json:"name"`
+	Parameters      []ParameterInfo `json:"parameters"`
+	Returns         []ParameterInfo `json:"returns"`
+	IsExported      bool            `json:"is_exported"`
+	PointerReceiver bool            `json:"pointer_receiver"`
+	// PromotedFrom is the embedded type declaring a promoted method.
+	PromotedFrom string   `json:"promoted_from,omitempty"`
+	Position     Position `json:"position,omitzero"`
 }
 
 // TypeInfo represents a type declaration.
 type TypeInfo struct {
-	Name       string       `json:"name"`
-	Kind       string       `json:"kind"`
-	IsExported bool         `json:"is_exported"`
-	Fields     []FieldInfo  `json:"fields,omitempty"`
-	Methods    []MethodInfo `json:"methods,omitempty"`
+	Name       string          `json:"name"`
+	Kind       string          `json:"kind"`
+	IsExported bool            `json:"is_exported"`
+	TypeParams []TypeParamInfo `json:"type_params,omitempty"`
+	Fields     []FieldInfo     `json:"fields,omitempty"`
+	Methods    []MethodInfo    `json:"methods,omitempty"`
+	// MethodSet holds the methods of the type and its pointer, including
+	// promoted methods.
+	MethodSet  []MethodInfo `json:"method_set,omitempty"`
 	Embeds     []string     `json:"embeds,omitempty"`
 	Implements []string     `json:"implements,omitempty"`
-	Position   Position     `json:"position"`
-	Doc        string       `json:"doc,omitempty"`
+	// PointerImplements lists the interfaces only the pointer type implements.
+	PointerImplements []string `json:"pointer_implements,omitempty"`
+	Position          Position `json:"position"`
+	Doc               string   `json:"doc,omitempty"`
 }
 
 // VariableInfo represents a variable or constant declaration.
 type VariableInfo struct {
-	Name       string   `json:"name"`
-	Type       string   `json:"type,omitempty"`
-	IsExported bool     `json:"is_exported"`
-	IsConst    bool     `json:"is_const"`
-	Value      string   `json:"value,omitempty"`
-	InFunction string   `json:"in_function,omitempty"`
-	Position   Position `json:"position"`
+	Name       string      `json:"name"`
+	Type       string      `json:"type,omitempty"`
+	TypeInfo   *TypeDetail `json:"type_info,omitempty"`
+	IsExported bool        `json:"is_exported"`
+	IsConst    bool        `json:"is_const"`
+	Value      string      `json:"value,omitempty"`
+	InFunction string      `json:"in_function,omitempty"`
+	Position   Position    `json:"position"`
 }
 
-// CallInfo represents a function or method call.
+// AssignmentInfo represents an assignment, := definition or initialized var
+// declaration inside a function, one per target. When a single value such as
+// a call result is assigned to several targets, ValueIndex is the position of
+// the target's element in it. Type is the resolved type of the target, or of
+// the discarded value when the target is the blank identifier.
+type AssignmentInfo struct {
+	Target      string   `json:"target"`
+	Type        string   `json:"type,omitempty"`
+	IsDiscarded bool     `json:"is_discarded"`
+	Value       string   `json:"value"`
+	ValueIndex  int      `json:"value_index,omitempty"`
+	Op          string   `json:"op"`
+	InFunction  string   `json:"in_function"`
+	Position    Position `json:"position"`
+}
+
+// CallInfo represents a function or method call. With type information,
+// Package is the import path of the package declaring the callee; without
+// it, Package is the qualifier as written.
 type CallInfo struct {
-	Function     string   `json:"function"`
-	Package      string   `json:"package,omitempty"`
-	Receiver     string   `json:"receiver,omitempty"`
-	ReceiverType string   `json:"receiver_type,omitempty"`
-	Args         []string `json:"args,omitempty"`
-	InFunction   string   `json:"in_function"`
-	Position     Position `json:"position"`
+	Function     string `json:"function"`
+	Package      string `json:"package,omitempty"`
+	Receiver     string `json:"receiver,omitempty"`
+	ReceiverType string `json:"receiver_type,omitempty"`
+	// Callee is the fully qualified callee, e.g. net/http.(*Client).Do, or the
+	// target type of a conversion.
+	Callee string `json:"callee,omitempty"`
+	// Kind is function, method, interface_method, builtin, conversion or
+	// dynamic for calls of function values.
+	Kind string   `json:"kind,omitempty"`
+	Args []string `json:"args,omitempty"`
+	// TypeArgs are the type arguments of a call of a generic function,
+	// whether explicit or inferred.
+	TypeArgs []string `json:"type_args,omitempty"`
+	// InFunction is the enclosing function, or a closure named as by the Go
+	// runtime (Outer.func1) whose enclosing function is ParentFunction. It is
+	// empty for package-level initializers.
+	InFunction     string `json:"in_function"`
+	ParentFunction string `json:"parent_function,omitempty"`
+	// InInit is set for calls made during package initialization, in
+	// package-level initializers and init functions.
+	InInit bool `json:"in_init"`
+	// InGo and InDefer are set for calls started by a go or defer statement
+	// and for calls inside a function literal started by one.
+	InGo     bool     `json:"in_go"`
+	InDefer  bool     `json:"in_defer"`
+	InLoop   bool     `json:"in_loop"`
+	InSelect bool     `json:"in_select"`
+	Position Position `json:"position"`
 }
 
 // TypeUsageInfo represents a reference to a type.
@@ -128,35 +257,89 @@ type TypeUsageInfo struct {
 	Position   Position `json:"position"`
 }
 
-// FieldAccessInfo represents a field access expression.
+// FieldAccessInfo represents a field access expression. Type and Package
+// name the struct type declaring the field, which for promoted fields is the
+// embedded type.
 type FieldAccessInfo struct {
 	Field      string   `json:"field"`
 	Receiver   string   `json:"receiver"`
 	Type       string   `json:"type,omitempty"`
+	Package    string   `json:"package,omitempty"`
+	IsWrite    bool     `json:"is_write"`
+	InFunction string   `json:"in_function"`
+	Position   Position `json:"position"`
+}
+
+// TaintFlow is a path along which the result of a taint source reaches an
+// argument of a sink. Path lists the calls it goes through, from Source to
+// Sink. Positions name files by their full path, as a flow may cross
+// packages.
+type TaintFlow struct {
+	Source TaintStep   `json:"source"`
+	Sink   TaintStep   `json:"sink"`
+	Path   []TaintStep `json:"path"`
+}
+
+// TaintStep is a call along a taint flow. Kind is source, call or sink, and
+// Arg is the index of the tainted argument, -1 for the receiver.
+type TaintStep struct {
+	Kind       string   `json:"kind"`
+	Function   string   `json:"function"`
 	InFunction string   `json:"in_function"`
+	Arg        *int     `json:"arg,omitempty"`
 	Position   Position `json:"position"`
 }
 
 // Violation represents a policy violation returned by Rego evaluation.
 type Violation struct {
-	Message  string   `json:"message"`
-	Rule     string   `json:"rule"`
-	Severity string   `json:"severity,omitempty"`
-	Position Position `json:"position"`
-	Fix      *Fix     `json:"fix,omitempty"`
+	Message     string   `json:"message"`
+	Rule        string   `json:"rule"`
+	Severity    string   `json:"severity,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Position    Position `json:"position"`
+	Fix         *Fix     `json:"fix,omitempty"`
 }
 
-// Fix represents an auto-fix suggestion for a violation.
+// Fix represents an auto-fix suggestion for a violation. Ops are lowered to
+// Edits before a fix is reported.
 type Fix struct {
 	Description string    `json:"description"`
 	Edits       []FixEdit `json:"edits,omitempty"`
+	Ops         []FixOp   `json:"ops,omitempty"`
+}
+
+// FixOp is a structured fix operation that regolint turns into edits using
+// the syntax tree of the file at Position, or of the violation's file.
+type FixOp struct {
+	// Op is one of add_import, remove_import, rename_identifier,
+	// insert_doc_comment, add_struct_tag or replace_call.
+	Op       string   `json:"op"`
+	Position Position `json:"position,omitzero"`
+	// Path is the import path for add_import and remove_import.
+	Path string `json:"path,omitempty"`
+	// Name is the import name for add_import and remove_import, or the
+	// identifier to rename for rename_identifier.
+	Name    string `json:"name,omitempty"`
+	NewName string `json:"new_name,omitempty"`
+	// Text is the comment for insert_doc_comment, without comment markers.
+	Text  string `json:"text,omitempty"`
+	Key   string `json:"key,omitempty"`
+	Value string `json:"value,omitempty"`
+	// Function and Args replace the callee and arguments for replace_call.
+	Function string   `json:"function,omitempty"`
+	Args     []string `json:"args,omitempty"`
 }
 
-// FixEdit represents a single text edit to fix a violation.
+// FixEdit represents a single text edit to fix a violation. It replaces the
+// range from Position (or Offset) up to, but not including, End (or
+// EndOffset). Without an end, the range covers OldText.
 type FixEdit struct {
-	Position Position `json:"position"`
-	OldText  string   `json:"old_text,omitempty"`
-	NewText  string   `json:"new_text"`
+	Position  Position  `json:"position"`
+	End       *Position `json:"end,omitempty"`
+	Offset    *int      `json:"offset,omitempty"`
+	EndOffset *int      `json:"end_offset,omitempty"`
+	OldText   string    `json:"old_text,omitempty"`
+	NewText   string    `json:"new_text"`
 }
 
 // PackageContext aggregates CodeContext from all files in a package.
@@ -171,3 +354,44 @@ type PackageContext struct {
 	AllConstants []VariableInfo `json:"all_constants"`
 	AllCalls     []CallInfo     `json:"all_calls"`
 }
+
+// ModuleContext is the input to module-wide rules: a summary of every
+// analyzed package and the imports between packages of the module. Positions
+// name files by their full path, since base names are ambiguous across
+// packages.
+type ModuleContext struct {
+	ModulePath string           `json:"module_path"`
+	Packages   []PackageSummary `json:"packages"`
+	Imports    []ImportEdge     `json:"imports"`
+}
+
+// PackageSummary describes one analyzed package in a ModuleContext.
+type PackageSummary struct {
+	Path      string       `json:"path"`
+	Name      string       `json:"name"`
+	Doc       string       `json:"doc,omitempty"`
+	Files     []string     `json:"files"`
+	Imports   []string     `json:"imports"`
+	Types     []SymbolInfo `json:"types"`
+	Functions []SymbolInfo `json:"functions"`
+	// Position is the package clause of the first file.
+	Position Position `json:"position"`
+}
+
+// SymbolInfo is a type or function declared by a package in a ModuleContext.
+// Kind is the kind of a type, or function or method.
+type SymbolInfo struct {
+	Name          string   `json:"name"`
+	QualifiedName string   `json:"qualified_name,omitempty"`
+	Kind          string   `json:"kind"`
+	Receiver      string   `json:"receiver,omitempty"`
+	IsExported    bool     `json:"is_exported"`
+	Position      Position `json:"position"`
+}
+
+// ImportEdge is an import of a package of the module by another one.
+type ImportEdge struct {
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	Position Position `json:"position"`
+}
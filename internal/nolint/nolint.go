@@ -143,8 +143,11 @@ type modelViolationAdapter struct {
 	v model.Violation
 }
 
+// GetRule returns the rule ID of the violation.
 func (a modelViolationAdapter) GetRule() string { return a.v.Rule }
-func (a modelViolationAdapter) GetLine() int    { return a.v.Position.Line }
+
+// GetLine returns the line the violation is reported on.
+func (a modelViolationAdapter) GetLine() int { return a.v.Position.Line }
 
 // FilterModelViolations filters model.Violation slice using nolint directives.
 func FilterModelViolations(violations []model.Violation, nolints []model.NolintDirective) []model.Violation {
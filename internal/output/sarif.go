@@ -48,6 +48,26 @@ type sarifResult struct {
 	Level     string          `json:"level"`
 	Message   sarifMessage    `json:"message"`
 	Locations []sarifLocation `json:"locations"`
+	Fixes     []sarifFix      `json:"fixes,omitempty"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description,omitzero"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion           `json:"deletedRegion"`
+	InsertedContent *sarifArtifactContent `json:"insertedContent,omitempty"`
+}
+
+type sarifArtifactContent struct {
+	Text string `json:"text"`
 }
 
 type sarifMessage struct {
@@ -70,6 +90,8 @@ type sarifArtifactLocation struct {
 type sarifRegion struct {
 	StartLine   int `json:"startLine"`
 	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
 }
 
 // WriteSARIF writes violations in SARIF format.
@@ -78,19 +100,9 @@ func WriteSARIF(w io.Writer, violations []model.Violation, version string) error
 	results := make([]sarifResult, 0, len(violations))
 
 	for _, v := range violations {
-		var level string
-		switch v.Severity {
-		case "warning":
-			level = "warning"
-		case "info":
-			level = "note"
-		default:
-			level = "error"
-		}
-
 		results = append(results, sarifResult{
 			RuleID:  v.Rule,
-			Level:   level,
+			Level:   sarifLevel(v.Severity),
 			Message: sarifMessage{Text: v.Message},
 			Locations: []sarifLocation{{
 				PhysicalLocation: sarifPhysicalLocation{
@@ -101,6 +113,7 @@ func WriteSARIF(w io.Writer, violations []model.Violation, version string) error
 					},
 				},
 			}},
+			Fixes: sarifFixes(v.Fix),
 		})
 	}
 
@@ -125,6 +138,63 @@ func WriteSARIF(w io.Writer, violations []model.Violation, version string) error
 	return enc.Encode(log)
 }
 
+func sarifLevel(severity string) string {
+	switch severity {
+	case "warning":
+		return "warning"
+	case "info":
+		return "note"
+	default:
+		return "error"
+	}
+}
+
+// sarifFixes converts the edits of a fix to a SARIF fix, grouping them by
+// file. Only edits with a resolved end position can be expressed as regions.
+func sarifFixes(fix *model.Fix) []sarifFix {
+	if fix == nil {
+		return nil
+	}
+
+	var changes []sarifArtifactChange
+	index := make(map[string]int)
+	for _, e := range fix.Edits {
+		if e.End == nil {
+			return nil
+		}
+
+		replacement := sarifReplacement{
+			DeletedRegion: sarifRegion{
+				StartLine:   e.Position.Line,
+				StartColumn: e.Position.Column,
+				EndLine:     e.End.Line,
+				EndColumn:   e.End.Column,
+			},
+		}
+		if e.NewText != "" {
+			replacement.InsertedContent = &sarifArtifactContent{Text: e.NewText}
+		}
+
+		i, ok := index[e.Position.File]
+		if !ok {
+			i = len(changes)
+			index[e.Position.File] = i
+			changes = append(changes, sarifArtifactChange{
+				ArtifactLocation: sarifArtifactLocation{URI: e.Position.File},
+			})
+		}
+		changes[i].Replacements = append(changes[i].Replacements, replacement)
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+	return []sarifFix{{
+		Description:     sarifMessage{Text: fix.Description},
+		ArtifactChanges: changes,
+	}}
+}
+
 func extractRules(violations []model.Violation) []sarifRule {
 	seen := make(map[string]bool)
 	var rules []sarifRule
@@ -135,9 +205,14 @@ func extractRules(violations []model.Violation) []sarifRule {
 		}
 		seen[v.Rule] = true
 
+		description := v.Description
+		if description == "" {
+			description = v.Rule
+		}
+
 		rules = append(rules, sarifRule{
 			ID:               v.Rule,
-			ShortDescription: sarifMessage{Text: v.Rule},
+			ShortDescription: sarifMessage{Text: description},
 		})
 	}
 
@@ -0,0 +1,93 @@
+// Package structtag parses struct tags into their keys and values, reporting
+// the malformations that reflect.StructTag silently ignores.
+package structtag
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/burdzwastaken/regolint/internal/model"
+)
+
+// Parse splits tag, in the conventional key:"value" format, into a map from
+// key to value. Parsing stops at the first syntax error, keeping the pairs
+// before it; a pair not followed by a space is kept, but nothing after it is
+// parsed. Each problem found is described in the returned diagnostics; a
+// repeated key keeps its first value, as reflect.StructTag.Get does.
+func Parse(tag string) (map[string]model.TagValue, []string) {
+	values := make(map[string]model.TagValue)
+	var diagnostics []string
+
+	for {
+		tag = strings.TrimLeft(tag, " ")
+		if tag == "" {
+			break
+		}
+
+		key, value, rest, diagnostic := next(tag)
+		if diagnostic != "" {
+			return values, append(diagnostics, diagnostic)
+		}
+		tag = rest
+
+		if _, ok := values[key]; ok {
+			diagnostics = append(diagnostics, fmt.Sprintf("struct tag repeats key %q", key))
+		} else {
+			values[key] = split(value)
+		}
+		if tag != "" && tag[0] != ' ' {
+			return values, append(diagnostics, `key:"value" pairs not separated by spaces`)
+		}
+	}
+
+	return values, diagnostics
+}
+
+// next reads the key:"value" pair at the start of tag, returning its key,
+// unquoted value and the remainder of tag, or a description of why the pair
+// is malformed.
+func next(tag string) (key, value, rest, diagnostic string) {
+	i := 0
+	for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+		i++
+	}
+	if i == 0 {
+		return "", "", "", "bad syntax for struct tag key"
+	}
+	if i+1 >= len(tag) || tag[i] != ':' {
+		return "", "", "", "bad syntax for struct tag pair"
+	}
+	if tag[i+1] != '"' {
+		return "", "", "", "bad syntax for struct tag value"
+	}
+	key = tag[:i]
+	tag = tag[i+1:]
+
+	i = 1
+	for i < len(tag) && tag[i] != '"' {
+		if tag[i] == '\\' {
+			i++
+		}
+		i++
+	}
+	if i >= len(tag) {
+		return "", "", "", "bad syntax for struct tag value"
+	}
+	value, err := strconv.Unquote(tag[:i+1])
+	if err != nil {
+		return "", "", "", "bad syntax for struct tag value"
+	}
+	return key, value, tag[i+1:], ""
+}
+
+// split divides a tag value into the name before its first comma and the
+// options after it.
+func split(value string) model.TagValue {
+	name, rest, found := strings.Cut(value, ",")
+	options := make([]string, 0)
+	if found {
+		options = strings.Split(rest, ",")
+	}
+	return model.TagValue{Value: value, Name: name, Options: options}
+}
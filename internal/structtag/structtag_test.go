@@ -0,0 +1,113 @@
+package structtag_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/burdzwastaken/regolint/internal/model"
+	"github.com/burdzwastaken/regolint/internal/structtag"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		tag         string
+		want        map[string]model.TagValue
+		diagnostics []string
+	}{
+		{
+			name: "empty",
+			tag:  "",
+			want: map[string]model.TagValue{},
+		},
+		{
+			name: "name and options",
+			tag:  `json:"id,omitempty,string" db:"user_id"`,
+			want: map[string]model.TagValue{
+				"json": {Value: "id,omitempty,string", Name: "id", Options: []string{"omitempty", "string"}},
+				"db":   {Value: "user_id", Name: "user_id", Options: []string{}},
+			},
+		},
+		{
+			name: "options only",
+			tag:  `json:",omitempty"`,
+			want: map[string]model.TagValue{
+				"json": {Value: ",omitempty", Name: "", Options: []string{"omitempty"}},
+			},
+		},
+		{
+			name: "escaped quote",
+			tag:  `validate:"eq=\"a b\""`,
+			want: map[string]model.TagValue{
+				"validate": {Value: `eq="a b"`, Name: `eq="a b"`, Options: []string{}},
+			},
+		},
+		{
+			name: "key that merely ends in json",
+			tag:  `xjson:"id"`,
+			want: map[string]model.TagValue{
+				"xjson": {Value: "id", Name: "id", Options: []string{}},
+			},
+		},
+		{
+			name:        "repeated key",
+			tag:         `json:"a" json:"b"`,
+			want:        map[string]model.TagValue{"json": {Value: "a", Name: "a", Options: []string{}}},
+			diagnostics: []string{`struct tag repeats key "json"`},
+		},
+		{
+			name:        "pairs not separated",
+			tag:         `json:"a"xml:"b"`,
+			want:        map[string]model.TagValue{"json": {Value: "a", Name: "a", Options: []string{}}},
+			diagnostics: []string{`key:"value" pairs not separated by spaces`},
+		},
+		{
+			name:        "pairs after unseparated pair",
+			tag:         `json:"a"xml:"b" db:"c"`,
+			want:        map[string]model.TagValue{"json": {Value: "a", Name: "a", Options: []string{}}},
+			diagnostics: []string{`key:"value" pairs not separated by spaces`},
+		},
+		{
+			name:        "missing colon",
+			tag:         `json:"a" omitempty`,
+			want:        map[string]model.TagValue{"json": {Value: "a", Name: "a", Options: []string{}}},
+			diagnostics: []string{"bad syntax for struct tag pair"},
+		},
+		{
+			name:        "unquoted value",
+			tag:         `json:id`,
+			want:        map[string]model.TagValue{},
+			diagnostics: []string{"bad syntax for struct tag value"},
+		},
+		{
+			name:        "unterminated value",
+			tag:         `json:"id`,
+			want:        map[string]model.TagValue{},
+			diagnostics: []string{"bad syntax for struct tag value"},
+		},
+		{
+			name:        "missing key",
+			tag:         `:"id"`,
+			want:        map[string]model.TagValue{},
+			diagnostics: []string{"bad syntax for struct tag key"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diagnostics := structtag.Parse(tt.tag)
+			if len(got) != len(tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.tag, got, tt.want)
+			}
+			for key, want := range tt.want {
+				value, ok := got[key]
+				if !ok || value.Value != want.Value || value.Name != want.Name || !slices.Equal(value.Options, want.Options) {
+					t.Errorf("Parse(%q)[%q] = %+v, want %+v", tt.tag, key, value, want)
+				}
+			}
+			if !slices.Equal(diagnostics, tt.diagnostics) {
+				t.Errorf("Parse(%q) diagnostics = %q, want %q", tt.tag, diagnostics, tt.diagnostics)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package taint
+
+import (
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// canonicalName rewrites a method named as go/types does, such as
+// (*net/http.Request).FormValue, to the form callees are named in,
+// net/http.(*Request).FormValue. Other names are returned unchanged.
+func canonicalName(name string) string {
+	end := strings.Index(name, ")")
+	if !strings.HasPrefix(name, "(") || end < 0 {
+		return name
+	}
+	recv, ptr := strings.CutPrefix(name[1:end], "*")
+	dot := strings.LastIndex(recv, ".")
+	if dot < 0 {
+		return name
+	}
+	typ := recv[dot+1:]
+	if ptr {
+		typ = "(*" + typ + ")"
+	}
+	return recv[:dot] + "." + typ + name[end+1:]
+}
+
+// Unresolved returns the sources, sinks and sanitizers of s that name no
+// function or method of the packages of prog or their dependencies, and so
+// can never match a call.
+func (s Spec) Unresolved(prog *ssa.Program) []string {
+	pkgs := make(map[string]*types.Package)
+	var add func(pkg *types.Package)
+	add = func(pkg *types.Package) {
+		if pkgs[pkg.Path()] != nil {
+			return
+		}
+		pkgs[pkg.Path()] = pkg
+		for _, imp := range pkg.Imports() {
+			add(imp)
+		}
+	}
+	for _, pkg := range prog.AllPackages() {
+		add(pkg.Pkg)
+	}
+
+	names := slices.Concat(s.Sources, s.Sanitizers)
+	for _, sink := range s.Sinks {
+		names = append(names, sink.Function)
+	}
+
+	var unresolved []string
+	for _, name := range names {
+		if !resolves(pkgs, canonicalName(name)) {
+			unresolved = append(unresolved, name)
+		}
+	}
+	slices.Sort(unresolved)
+	return slices.Compact(unresolved)
+}
+
+// resolves reports whether name, in the form callees are named in, is a
+// function or method of one of pkgs.
+func resolves(pkgs map[string]*types.Package, name string) bool {
+	for path, pkg := range pkgs {
+		if member, ok := strings.CutPrefix(name, path+"."); ok && isFunc(pkg, member) {
+			return true
+		}
+	}
+	return false
+}
+
+// isFunc reports whether pkg declares the function Func, or the method
+// Type.Method or (*Type).Method, named by member.
+func isFunc(pkg *types.Package, member string) bool {
+	typeName, method, isMethod := strings.Cut(member, ".")
+	if !isMethod {
+		_, ok := pkg.Scope().Lookup(member).(*types.Func)
+		return ok
+	}
+
+	typeName, ptr := strings.CutPrefix(typeName, "(*")
+	if ptr {
+		typeName = strings.TrimSuffix(typeName, ")")
+	}
+	tn, ok := pkg.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return false
+	}
+	// Callees name methods by their declared receiver, so a method of T
+	// is not (*T).Method.
+	typ := tn.Type()
+	if !types.IsInterface(typ) {
+		typ = types.NewPointer(typ)
+	}
+	obj, _, _ := types.LookupFieldOrMethod(typ, false, pkg, method)
+	fn, ok := obj.(*types.Func)
+	if !ok || fn.Pkg() != pkg {
+		return false
+	}
+	_, ptrRecv := fn.Signature().Recv().Type().(*types.Pointer)
+	return ptrRecv == ptr
+}
@@ -0,0 +1,407 @@
+// Package taint tracks values from taint sources to sinks through the SSA
+// form of the analyzed packages.
+package taint
+
+import (
+	"cmp"
+	"go/ast"
+	"go/token"
+	"slices"
+
+	"github.com/burdzwastaken/regolint/internal/callgraph"
+	"github.com/burdzwastaken/regolint/internal/model"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// Spec declares the functions taint flows start, end and stop at. Functions
+// are named as callees are, such as net/http.(*Request).FormValue, or as
+// go/types names them, such as (*net/http.Request).FormValue.
+// nolint:TAG001 // not serialized
+type Spec struct {
+	// Sources return tainted values.
+	Sources []string
+	// Sinks must not receive tainted arguments.
+	Sinks []Sink
+	// Sanitizers return untainted values whatever their arguments.
+	Sanitizers []string
+}
+
+// Sink is a function some of whose arguments must not be tainted.
+// nolint:TAG001 // not serialized
+type Sink struct {
+	Function string
+	// Args are the indexes of the checked arguments, not counting the
+	// receiver. All arguments are checked when it is empty.
+	Args []int
+}
+
+// Empty reports whether s declares no sources or no sinks, so that no flow
+// can be found.
+func (s Spec) Empty() bool {
+	return len(s.Sources) == 0 || len(s.Sinks) == 0
+}
+
+// analyzer finds flows in one program.
+type analyzer struct {
+	prog       *ssa.Program
+	sources    map[string]bool
+	sinks      map[string][]int
+	sanitizers map[string]bool
+	summaries  map[summaryKey]*summary
+	calls      map[*ast.BlockStmt]map[token.Pos]token.Pos
+}
+
+// summaryKey identifies a function analyzed with one tainted parameter or
+// free variable.
+type summaryKey struct {
+	fn   *ssa.Function
+	seed ssa.Value
+}
+
+// summary is what taint entering a function through one parameter or free
+// variable does: whether it is returned, and the sinks it reaches.
+type summary struct {
+	returns bool
+	hits    [][]model.TaintStep
+}
+
+// Analyze returns the flows from sources to sinks in the functions of prog
+// built from source, sorted by the position of their sinks. A value is
+// tainted when it is computed from a tainted value, stored in a variable,
+// field, element or map entry along with one, or returned by a call
+// receiving one. Calls of functions built from source are followed into
+// their bodies.
+func Analyze(prog *ssa.Program, spec Spec) []model.TaintFlow {
+	a := newAnalyzer(prog, spec)
+
+	flows := make([]model.TaintFlow, 0)
+	seen := make(map[[2]model.Position]bool)
+	for _, fn := range sourceFunctions(prog) {
+		for _, call := range a.findSources(fn) {
+			seed := map[ssa.Value][]model.TaintStep{
+				call: {a.step("source", calleeName(&call.Call), fn, call, nil)},
+			}
+			for _, path := range a.propagate(fn, seed).hits {
+				flow := model.TaintFlow{Source: path[0], Sink: path[len(path)-1], Path: path}
+				key := [2]model.Position{flow.Source.Position, flow.Sink.Position}
+				if !seen[key] {
+					seen[key] = true
+					flows = append(flows, flow)
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(flows, func(a, b model.TaintFlow) int {
+		return cmp.Or(comparePositions(a.Sink.Position, b.Sink.Position), comparePositions(a.Source.Position, b.Source.Position))
+	})
+	return flows
+}
+
+func newAnalyzer(prog *ssa.Program, spec Spec) *analyzer {
+	a := &analyzer{
+		prog:       prog,
+		sources:    make(map[string]bool),
+		sinks:      make(map[string][]int),
+		sanitizers: make(map[string]bool),
+		summaries:  make(map[summaryKey]*summary),
+		calls:      make(map[*ast.BlockStmt]map[token.Pos]token.Pos),
+	}
+	for _, name := range spec.Sources {
+		a.sources[canonicalName(name)] = true
+	}
+	for _, sink := range spec.Sinks {
+		name := canonicalName(sink.Function)
+		checked, ok := a.sinks[name]
+		if len(sink.Args) == 0 || ok && len(checked) == 0 {
+			a.sinks[name] = []int{}
+		} else {
+			a.sinks[name] = append(checked, sink.Args...)
+		}
+	}
+	for _, name := range spec.Sanitizers {
+		a.sanitizers[canonicalName(name)] = true
+	}
+	return a
+}
+
+// sourceFunctions returns the functions of prog built from source in a fixed
+// order, as the summaries of recursive functions depend on which is entered
+// first.
+func sourceFunctions(prog *ssa.Program) []*ssa.Function {
+	var fns []*ssa.Function
+	for fn := range ssautil.AllFunctions(prog) {
+		if fn.Blocks != nil {
+			fns = append(fns, fn)
+		}
+	}
+	slices.SortFunc(fns, func(a, b *ssa.Function) int {
+		return cmp.Or(cmp.Compare(a.Pos(), b.Pos()), cmp.Compare(a.String(), b.String()))
+	})
+	return fns
+}
+
+// findSources returns the calls of sources in fn.
+func (a *analyzer) findSources(fn *ssa.Function) []*ssa.Call {
+	var calls []*ssa.Call
+	for _, block := range fn.Blocks {
+		for _, instr := range block.Instrs {
+			if call, ok := instr.(*ssa.Call); ok && a.sources[calleeName(&call.Call)] {
+				calls = append(calls, call)
+			}
+		}
+	}
+	return calls
+}
+
+// summarize returns the summary of fn with seed tainted. Recursive calls see
+// the summary computed so far.
+func (a *analyzer) summarize(fn *ssa.Function, seed ssa.Value) *summary {
+	key := summaryKey{fn, seed}
+	if s, ok := a.summaries[key]; ok {
+		return s
+	}
+	s := &summary{}
+	a.summaries[key] = s
+	*s = a.propagate(fn, map[ssa.Value][]model.TaintStep{seed: nil})
+	return s
+}
+
+// flow is the state of propagating taint through one function.
+type flow struct {
+	fn      *ssa.Function
+	tainted map[ssa.Value][]model.TaintStep
+	queue   []ssa.Value
+	result  summary
+}
+
+// mark taints v, reached by path, unless it already is.
+func (f *flow) mark(v ssa.Value, path []model.TaintStep) {
+	if _, ok := f.tainted[v]; ok || v == nil {
+		return
+	}
+	f.tainted[v] = path
+	f.queue = append(f.queue, v)
+}
+
+// propagate follows the seeds of fn through its instructions. The path of
+// each tainted value lists the calls it went through, starting from the path
+// of its seed.
+func (a *analyzer) propagate(fn *ssa.Function, seeds map[ssa.Value][]model.TaintStep) summary {
+	f := &flow{fn: fn, tainted: make(map[ssa.Value][]model.TaintStep)}
+	for v, path := range seeds {
+		f.mark(v, path)
+	}
+
+	for len(f.queue) > 0 {
+		v := f.queue[0]
+		f.queue = f.queue[1:]
+		if v.Referrers() == nil {
+			continue
+		}
+		for _, instr := range *v.Referrers() {
+			a.visit(f, instr, v)
+		}
+	}
+	return f.result
+}
+
+// visit propagates the taint of v through instr.
+func (a *analyzer) visit(f *flow, instr ssa.Instruction, v ssa.Value) {
+	path := f.tainted[v]
+	switch instr := instr.(type) {
+	case ssa.CallInstruction:
+		a.checkSinks(f, instr, v)
+		a.visitCall(f, instr, v)
+	case *ssa.Store:
+		if instr.Val == v {
+			for addr := instr.Addr; addr != nil; addr = baseAddr(addr) {
+				f.mark(addr, path)
+			}
+		}
+	case *ssa.MapUpdate:
+		f.mark(instr.Map, path)
+	case *ssa.Send:
+		if instr.X == v {
+			f.mark(instr.Chan, path)
+		}
+	case *ssa.MakeClosure:
+		closure := instr.Fn.(*ssa.Function)
+		for i, binding := range instr.Bindings {
+			if binding == v {
+				s := a.summarize(closure, closure.FreeVars[i])
+				f.result.hits = append(f.result.hits, joinPaths(path, s.hits)...)
+				if s.returns {
+					f.mark(instr, path)
+				}
+			}
+		}
+	case *ssa.Return:
+		f.result.returns = true
+	case ssa.Value:
+		f.mark(instr, path)
+	}
+}
+
+// checkSinks records a hit if v is a checked argument of a sink called by
+// instr.
+func (a *analyzer) checkSinks(f *flow, instr ssa.CallInstruction, v ssa.Value) {
+	common := instr.Common()
+	name := calleeName(common)
+	checked, ok := a.sinks[name]
+	if !ok {
+		return
+	}
+
+	args := common.Args
+	if !common.IsInvoke() && common.Signature().Recv() != nil {
+		args = args[1:]
+	}
+	for i, arg := range args {
+		if arg == v && (len(checked) == 0 || slices.Contains(checked, i)) {
+			f.result.hits = append(f.result.hits, appendStep(f.tainted[v], a.step("sink", name, f.fn, instr, &i)))
+		}
+	}
+}
+
+// visitCall propagates the taint of v, an argument, receiver or callee of
+// instr, into the body of the function called, if it was built from source,
+// and to the value of instr unless a sanitizer is called. Other calls are
+// assumed to return tainted values when given one.
+func (a *analyzer) visitCall(f *flow, instr ssa.CallInstruction, v ssa.Value) {
+	common := instr.Common()
+	name := calleeName(common)
+	if a.sanitizers[name] {
+		return
+	}
+	result, _ := instr.(*ssa.Call)
+
+	if b, ok := common.Value.(*ssa.Builtin); ok && b.Name() == "copy" {
+		if common.Args[1] == v {
+			f.mark(common.Args[0], f.tainted[v])
+		}
+		return
+	}
+
+	callee := common.StaticCallee()
+	if callee == nil || callee.Blocks == nil {
+		if result != nil {
+			f.mark(result, f.tainted[v])
+		}
+		return
+	}
+
+	for i, arg := range common.Args {
+		if arg != v {
+			continue
+		}
+		index := i
+		if callee.Signature.Recv() != nil {
+			index--
+		}
+		callPath := appendStep(f.tainted[v], a.step("call", name, f.fn, instr, &index))
+		s := a.summarize(callee, callee.Params[i])
+		f.result.hits = append(f.result.hits, joinPaths(callPath, s.hits)...)
+		if s.returns && result != nil {
+			f.mark(result, callPath)
+		}
+	}
+}
+
+// baseAddr returns the address of the variable, struct or array that addr
+// points into, or nil.
+func baseAddr(addr ssa.Value) ssa.Value {
+	switch addr := addr.(type) {
+	case *ssa.FieldAddr:
+		return addr.X
+	case *ssa.IndexAddr:
+		return addr.X
+	default:
+		return nil
+	}
+}
+
+// calleeName returns the name of the function call calls, or "" when it is
+// dynamic.
+func calleeName(call *ssa.CallCommon) string {
+	switch {
+	case call.IsInvoke():
+		return callgraph.Name(call.Method)
+	case call.StaticCallee() != nil:
+		return callgraph.NameOf(call.StaticCallee())
+	default:
+		if b, ok := call.Value.(*ssa.Builtin); ok {
+			return b.Name()
+		}
+		return ""
+	}
+}
+
+// step describes the call instr in fn. arg is the index of the tainted
+// argument, not counting the receiver, which is -1.
+func (a *analyzer) step(kind, name string, fn *ssa.Function, instr ssa.CallInstruction, arg *int) model.TaintStep {
+	return model.TaintStep{
+		Kind:       kind,
+		Function:   name,
+		InFunction: callgraph.NameOf(fn),
+		Arg:        arg,
+		Position:   a.position(fn, instr.Common().Pos()),
+	}
+}
+
+// position converts the position of the opening parenthesis of a call in fn
+// to that of the call expression, as calls are positioned elsewhere.
+func (a *analyzer) position(fn *ssa.Function, lparen token.Pos) model.Position {
+	pos := lparen
+	if body := functionBody(fn); body != nil {
+		calls, ok := a.calls[body]
+		if !ok {
+			calls = make(map[token.Pos]token.Pos)
+			ast.Inspect(body, func(n ast.Node) bool {
+				if call, ok := n.(*ast.CallExpr); ok {
+					calls[call.Lparen] = call.Pos()
+				}
+				return true
+			})
+			a.calls[body] = calls
+		}
+		if start, ok := calls[lparen]; ok {
+			pos = start
+		}
+	}
+
+	p := a.prog.Fset.Position(pos)
+	return model.Position{File: p.Filename, Line: p.Line, Column: p.Column}
+}
+
+// functionBody returns the body fn was built from, or nil.
+func functionBody(fn *ssa.Function) *ast.BlockStmt {
+	switch syntax := fn.Syntax().(type) {
+	case *ast.FuncDecl:
+		return syntax.Body
+	case *ast.FuncLit:
+		return syntax.Body
+	default:
+		return nil
+	}
+}
+
+// appendStep returns a copy of path with step appended, so that paths
+// sharing a prefix do not share storage.
+func appendStep(path []model.TaintStep, step model.TaintStep) []model.TaintStep {
+	return append(slices.Clip(path), step)
+}
+
+// joinPaths prefixes each of the paths with prefix.
+func joinPaths(prefix []model.TaintStep, paths [][]model.TaintStep) [][]model.TaintStep {
+	joined := make([][]model.TaintStep, 0, len(paths))
+	for _, path := range paths {
+		joined = append(joined, slices.Concat(prefix, path))
+	}
+	return joined
+}
+
+func comparePositions(a, b model.Position) int {
+	return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line), cmp.Compare(a.Column, b.Column))
+}
@@ -0,0 +1,210 @@
+package taint_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/burdzwastaken/regolint/internal/callgraph"
+	"github.com/burdzwastaken/regolint/internal/model"
+	"github.com/burdzwastaken/regolint/internal/taint"
+	"github.com/burdzwastaken/regolint/internal/testenv"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+)
+
+const appSource = `package app
+
+import (
+	"database/sql"
+	"net/http"
+	"os/exec"
+	"strconv"
+)
+
+type Server struct{ db *sql.DB }
+
+func (s *Server) Search(w http.ResponseWriter, r *http.Request) {
+	q := r.FormValue("q")
+	s.db.Query("SELECT * FROM t WHERE name = '" + q + "'")
+}
+
+func (s *Server) Sanitized(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.FormValue("id"))
+	s.db.Query("SELECT * FROM t WHERE id = " + strconv.Itoa(id))
+}
+
+func (s *Server) Bound(w http.ResponseWriter, r *http.Request) {
+	s.db.Query("SELECT * FROM t WHERE name = ?", r.FormValue("q"))
+}
+
+func Run(w http.ResponseWriter, r *http.Request) {
+	run(r.FormValue("cmd"))
+}
+
+func run(name string) {
+	_ = exec.Command("sh", "-c", name).Run()
+}
+
+type query struct{ text string }
+
+func Field(r *http.Request, db *sql.DB) {
+	var q query
+	q.text = r.FormValue("q")
+	db.Query(q.text)
+}
+
+func Closure(r *http.Request, db *sql.DB) {
+	v := r.FormValue("v")
+	func() { db.Query(v) }()
+}
+`
+
+func loadProgram(t *testing.T) *ssa.Program {
+	t.Helper()
+	testenv.MustReadExportData(t)
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":     "module example.com/m\n\ngo 1.22\n",
+		"app/app.go": appSource,
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedSyntax |
+			packages.NeedTypes | packages.NeedTypesInfo,
+		Dir: dir,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		t.Fatalf("loading packages: %v", err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		t.Fatal("packages have errors")
+	}
+	return callgraph.Program(pkgs)
+}
+
+func TestAnalyze(t *testing.T) {
+	spec := taint.Spec{
+		Sources: []string{"net/http.(*Request).FormValue"},
+		Sinks: []taint.Sink{
+			{Function: "database/sql.(*DB).Query", Args: []int{0}},
+			{Function: "os/exec.Command"},
+		},
+		Sanitizers: []string{"strconv.Atoi"},
+	}
+	flows := taint.Analyze(loadProgram(t), spec)
+
+	type step struct {
+		kind, function, inFunction string
+	}
+	steps := func(flow model.TaintFlow) []step {
+		var s []step
+		for _, p := range flow.Path {
+			s = append(s, step{p.Kind, p.Function, p.InFunction})
+		}
+		return s
+	}
+
+	const (
+		formValue = "net/http.(*Request).FormValue"
+		query     = "database/sql.(*DB).Query"
+	)
+	want := [][]step{
+		{{"source", formValue, "example.com/m/app.(*Server).Search"}, {"sink", query, "example.com/m/app.(*Server).Search"}},
+		{{"source", formValue, "example.com/m/app.Run"}, {"call", "example.com/m/app.run", "example.com/m/app.Run"}, {"sink", "os/exec.Command", "example.com/m/app.run"}},
+		{{"source", formValue, "example.com/m/app.Field"}, {"sink", query, "example.com/m/app.Field"}},
+		{{"source", formValue, "example.com/m/app.Closure"}, {"sink", query, "example.com/m/app.Closure"}},
+	}
+
+	if len(flows) != len(want) {
+		t.Fatalf("expected %d flows, got %d: %+v", len(want), len(flows), flows)
+	}
+	for i, flow := range flows {
+		if got := steps(flow); !slices.Equal(got, want[i]) {
+			t.Errorf("flow %d: path = %+v, want %+v", i, got, want[i])
+		}
+		if flow.Source != flow.Path[0] || flow.Sink != flow.Path[len(flow.Path)-1] {
+			t.Errorf("flow %d: source and sink differ from the ends of its path", i)
+		}
+	}
+
+	sink := flows[0].Sink
+	if filepath.Base(sink.Position.File) != "app.go" || sink.Position.Line != 14 || sink.Position.Column != 2 {
+		t.Errorf("sink position = %+v, want app.go:14:2", sink.Position)
+	}
+	if sink.Arg == nil || *sink.Arg != 0 {
+		t.Errorf("sink arg = %v, want 0", sink.Arg)
+	}
+	if call := flows[1].Path[1]; call.Arg == nil || *call.Arg != 0 {
+		t.Errorf("call arg = %v, want 0", call.Arg)
+	}
+	if arg := flows[1].Sink.Arg; arg == nil || *arg != 1 {
+		t.Errorf("variadic sink arg = %v, want 1", arg)
+	}
+}
+
+func TestAnalyzeTypesSpelling(t *testing.T) {
+	spec := taint.Spec{
+		Sources: []string{"(*net/http.Request).FormValue"},
+		Sinks:   []taint.Sink{{Function: "(*database/sql.DB).Query", Args: []int{0}}},
+	}
+	prog := loadProgram(t)
+
+	flows := taint.Analyze(prog, spec)
+	if len(flows) != 4 {
+		t.Fatalf("expected 4 flows, got %d: %+v", len(flows), flows)
+	}
+	if got := flows[0].Source.Function; got != "net/http.(*Request).FormValue" {
+		t.Errorf("source function = %q, want the callee name", got)
+	}
+	if unresolved := spec.Unresolved(prog); len(unresolved) != 0 {
+		t.Errorf("Unresolved() = %v, want none", unresolved)
+	}
+}
+
+func TestSpecUnresolved(t *testing.T) {
+	spec := taint.Spec{
+		Sources: []string{"net/http.(*Request).FormValue", "net/http.(*Request).FormValues", "net/http.Request.FormValue", "net/http.Header.Get"},
+		Sinks: []taint.Sink{
+			{Function: "io.Writer.Write"},
+			{Function: "net/http.(*Header).Set"},
+			{Function: "os/exec.Command"},
+			{Function: "example.com/missing.Exec"},
+		},
+		Sanitizers: []string{"strconv.Atoi", "strconv.Atoii"},
+	}
+
+	want := []string{"example.com/missing.Exec", "net/http.(*Header).Set", "net/http.(*Request).FormValues", "net/http.Request.FormValue", "strconv.Atoii"}
+	if got := spec.Unresolved(loadProgram(t)); !slices.Equal(got, want) {
+		t.Errorf("Unresolved() = %v, want %v", got, want)
+	}
+}
+
+func TestSpecEmpty(t *testing.T) {
+	tests := []struct {
+		spec taint.Spec
+		want bool
+	}{
+		{taint.Spec{}, true},
+		{taint.Spec{Sources: []string{"os.Getenv"}}, true},
+		{taint.Spec{Sinks: []taint.Sink{{Function: "os/exec.Command"}}}, true},
+		{taint.Spec{Sources: []string{"os.Getenv"}, Sinks: []taint.Sink{{Function: "os/exec.Command"}}}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.spec.Empty(); got != tt.want {
+			t.Errorf("%+v.Empty() = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
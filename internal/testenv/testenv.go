@@ -0,0 +1,49 @@
+// Package testenv guards tests that need more from the environment than the
+// Go toolchain running them.
+package testenv
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/tools/go/gcexportdata"
+)
+
+// MustReadExportData skips t unless golang.org/x/tools can read the export
+// data written by the go command. packages.Load takes the types of
+// dependencies from export data and exits the process when it cannot read
+// it, as happens with a toolchain newer than the x/tools in go.mod supports.
+func MustReadExportData(t testing.TB) {
+	t.Helper()
+	if err := readExportData(); err != nil {
+		t.Skipf("cannot load packages with this Go toolchain and golang.org/x/tools; "+
+			"run them with an older toolchain, such as GOTOOLCHAIN=go1.25.5, or update x/tools: %v", err)
+	}
+}
+
+// readExportData reads the export data of a standard library package once.
+var readExportData = sync.OnceValue(func() error {
+	out, err := exec.Command("go", "list", "-export", "-f", "{{.Export}}", "errors").Output()
+	if err != nil {
+		return fmt.Errorf("go list: %w", err)
+	}
+
+	f, err := os.Open(strings.TrimSpace(string(out)))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	r, err := gcexportdata.NewReader(f)
+	if err != nil {
+		return err
+	}
+	_, err = gcexportdata.Read(r, token.NewFileSet(), make(map[string]*types.Package), "errors")
+	return err
+})
@@ -1,55 +1,295 @@
 package transformer
 
 import (
+	"cmp"
 	"go/ast"
+	"go/types"
+	"strconv"
 
+	"github.com/burdzwastaken/regolint/internal/callgraph"
 	"github.com/burdzwastaken/regolint/internal/model"
 )
 
-func (t *Transformer) extractCalls(fn *ast.FuncDecl, funcName string) []model.CallInfo {
-	if fn.Body == nil {
-		return nil
+// funcScope describes the function, closure or initializer code appears in.
+// Closures get the name the Go runtime gives them, such as Outer.func1 or
+// Outer.func1.1, and inherit the flags of the code that defines them.
+type funcScope struct {
+	function string
+	parent   string
+	closures *int
+	nested   bool
+	init     bool
+	inGo     bool
+	inDefer  bool
+	inLoop   bool
+	inSelect bool
+}
+
+// closure returns the scope of the next function literal defined in s.
+func (s funcScope) closure() funcScope {
+	*s.closures++
+	name := s.function + "." + strconv.Itoa(*s.closures)
+	if !s.nested {
+		name = cmp.Or(s.function, "init") + ".func" + strconv.Itoa(*s.closures)
 	}
+	return funcScope{
+		function: name,
+		parent:   s.function,
+		closures: new(int),
+		nested:   true,
+		inGo:     s.inGo,
+		inDefer:  s.inDefer,
+		inLoop:   s.inLoop,
+		inSelect: s.inSelect,
+	}
+}
 
-	calls := make([]model.CallInfo, 0)
+// bodyInfo collects what walkBodies finds in function bodies.
+type bodyInfo struct {
+	calls       []model.CallInfo
+	variables   []model.VariableInfo
+	constants   []model.VariableInfo
+	assignments []model.AssignmentInfo
+}
 
-	ast.Inspect(fn.Body, func(n ast.Node) bool {
-		callExpr, ok := n.(*ast.CallExpr)
-		if !ok {
-			return true
+// walkBodies records the calls in function bodies, function literals and
+// package-level initializers, along with local declarations and assignments.
+func (t *Transformer) walkBodies(file *ast.File) *bodyInfo {
+	out := &bodyInfo{
+		calls:       make([]model.CallInfo, 0),
+		assignments: make([]model.AssignmentInfo, 0),
+	}
+	pkgScope := funcScope{closures: new(int), init: true}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Body == nil {
+				continue
+			}
+			scope := funcScope{function: d.Name.Name, closures: new(int)}
+			scope.init = d.Recv == nil && d.Name.Name == "init"
+			t.walk(d.Body, scope, out)
+		case *ast.GenDecl:
+			t.walk(d, pkgScope, out)
 		}
+	}
+
+	return out
+}
+
+// walk records the calls and locals in node, descending into loops, selects,
+// go and defer statements and function literals with their own scopes.
+func (t *Transformer) walk(node ast.Node, s funcScope, out *bodyInfo) {
+	if node == nil {
+		return
+	}
 
-		call := model.CallInfo{
-			InFunction: funcName,
-			Position:   t.position(callExpr.Pos()),
-			Args:       t.extractCallArgs(callExpr),
+	ast.Inspect(node, func(n ast.Node) bool {
+		t.recordLocals(n, s, out)
+
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			t.walk(n.Body, s.closure(), out)
+		case *ast.ForStmt:
+			t.walk(n.Init, s, out)
+			loop := s
+			loop.inLoop = true
+			t.walk(n.Cond, loop, out)
+			t.walk(n.Post, loop, out)
+			t.walk(n.Body, loop, out)
+		case *ast.RangeStmt:
+			t.walk(n.X, s, out)
+			loop := s
+			loop.inLoop = true
+			t.walk(n.Body, loop, out)
+		case *ast.SelectStmt:
+			sel := s
+			sel.inSelect = true
+			t.walk(n.Body, sel, out)
+		case *ast.GoStmt:
+			launched := s
+			launched.inGo = true
+			t.walkCall(n.Call, s, launched, out)
+		case *ast.DeferStmt:
+			launched := s
+			launched.inDefer = true
+			t.walkCall(n.Call, s, launched, out)
+		case *ast.CallExpr:
+			t.walkCall(n, s, s, out)
+		default:
+			return true
 		}
+		return false
+	})
+}
+
+// walkCall records expr and the calls within it. The call itself runs in
+// scope call, which differs from s for go and defer statements, while its
+// arguments are evaluated in s.
+func (t *Transformer) walkCall(expr *ast.CallExpr, s, call funcScope, out *bodyInfo) {
+	out.calls = append(out.calls, t.callInfo(expr, call))
+	i := len(out.calls) - 1
+
+	if lit, ok := ast.Unparen(expr.Fun).(*ast.FuncLit); ok {
+		body := call.closure()
+		out.calls[i].Callee = body.function
+		t.walk(lit.Body, body, out)
+	} else {
+		t.walk(expr.Fun, s, out)
+	}
+	for _, arg := range expr.Args {
+		t.walk(arg, s, out)
+	}
+}
+
+func (t *Transformer) callInfo(expr *ast.CallExpr, s funcScope) model.CallInfo {
+	call := model.CallInfo{
+		InFunction:     s.function,
+		ParentFunction: s.parent,
+		InInit:         s.init,
+		InGo:           s.inGo,
+		InDefer:        s.inDefer,
+		InLoop:         s.inLoop,
+		InSelect:       s.inSelect,
+		Position:       t.position(expr.Pos()),
+		Args:           t.extractCallArgs(expr),
+	}
 
-		switch fun := callExpr.Fun.(type) {
+	switch fun := calleeExpr(expr.Fun).(type) {
+	case *ast.Ident:
+		call.Function = fun.Name
+	case *ast.SelectorExpr:
+		call.Function = fun.Sel.Name
+		switch x := fun.X.(type) {
 		case *ast.Ident:
-			call.Function = fun.Name
+			call.Package = x.Name
+			call.Receiver = x.Name
+		case *ast.CallExpr:
+			call.Receiver = "call"
 		case *ast.SelectorExpr:
-			call.Function = fun.Sel.Name
-			switch x := fun.X.(type) {
-			case *ast.Ident:
-				call.Package = x.Name
-				call.Receiver = x.Name
-			case *ast.CallExpr:
-				call.Receiver = "call"
-			case *ast.SelectorExpr:
-				call.Receiver = t.formatType(x)
+			call.Receiver = t.formatType(x)
+		}
+	case *ast.FuncLit:
+		call.Function = "(anonymous)"
+	case *ast.ParenExpr:
+		call.Function = "(conversion)"
+	}
+	t.resolveCall(expr, &call)
+
+	return call
+}
+
+// resolveCall replaces the syntactic guesses about the callee of expr with
+// type information when it is available.
+func (t *Transformer) resolveCall(expr *ast.CallExpr, call *model.CallInfo) {
+	info := t.pkg.TypesInfo
+	if info == nil {
+		return
+	}
+
+	fun := ast.Unparen(expr.Fun)
+	if tv, ok := info.Types[fun]; ok && tv.IsType() {
+		call.Kind = "conversion"
+		call.Callee = types.TypeString(tv.Type, nil)
+		call.Package = ""
+		return
+	}
+
+	sel, isSel := calleeExpr(fun).(*ast.SelectorExpr)
+	if isSel {
+		if x, ok := sel.X.(*ast.Ident); ok {
+			if _, ok := info.Uses[x].(*types.PkgName); ok {
+				call.Receiver = ""
 			}
-		case *ast.FuncLit:
-			call.Function = "(anonymous)"
-		case *ast.ParenExpr:
-			call.Function = "(conversion)"
 		}
+		if s, ok := info.Selections[sel]; ok && s.Kind() != types.FieldVal {
+			call.ReceiverType = types.TypeString(s.Recv(), nil)
+		}
+	}
 
-		calls = append(calls, call)
-		return true
-	})
+	call.TypeArgs = t.typeArgs(info, fun)
 
-	return calls
+	switch obj := calleeObject(info, fun).(type) {
+	case *types.Builtin:
+		call.Kind = "builtin"
+		call.Callee = obj.Name()
+		call.Package = ""
+	case *types.Func:
+		call.Kind = funcKind(obj)
+		call.Callee = callgraph.Name(obj)
+		call.Package = ""
+		if obj.Pkg() != nil {
+			call.Package = obj.Pkg().Path()
+		}
+	default:
+		if _, ok := info.Types[fun]; ok {
+			call.Kind = "dynamic"
+			call.Package = ""
+		}
+	}
+}
+
+// calleeExpr strips the explicit instantiation from a call of a generic
+// function.
+func calleeExpr(fun ast.Expr) ast.Expr {
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		return ast.Unparen(f.X)
+	case *ast.IndexListExpr:
+		return ast.Unparen(f.X)
+	default:
+		return fun
+	}
+}
+
+// calleeObject returns the object a call refers to, or nil when it calls the
+// result of an expression.
+func calleeObject(info *types.Info, fun ast.Expr) types.Object {
+	switch f := calleeExpr(fun).(type) {
+	case *ast.Ident:
+		return info.Uses[f]
+	case *ast.SelectorExpr:
+		return info.Uses[f.Sel]
+	default:
+		return nil
+	}
+}
+
+// typeArgs returns the type arguments, explicit or inferred, of a call of a
+// generic function.
+func (t *Transformer) typeArgs(info *types.Info, fun ast.Expr) []string {
+	var ident *ast.Ident
+	switch f := calleeExpr(fun).(type) {
+	case *ast.Ident:
+		ident = f
+	case *ast.SelectorExpr:
+		ident = f.Sel
+	default:
+		return nil
+	}
+
+	inst, ok := info.Instances[ident]
+	if !ok {
+		return nil
+	}
+	args := make([]string, inst.TypeArgs.Len())
+	for i := range args {
+		args[i] = types.TypeString(inst.TypeArgs.At(i), t.qualifier)
+	}
+	return args
+}
+
+func funcKind(fn *types.Func) string {
+	recv := fn.Signature().Recv()
+	switch {
+	case recv == nil:
+		return "function"
+	case types.IsInterface(recv.Type()):
+		return "interface_method"
+	default:
+		return "method"
+	}
 }
 
 func (t *Transformer) extractCallArgs(call *ast.CallExpr) []string {
@@ -0,0 +1,173 @@
+package transformer
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"go/token"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/burdzwastaken/regolint/internal/model"
+)
+
+// generatedPattern matches the comment that marks a file as generated, as
+// described at https://go.dev/s/generatedcode.
+var generatedPattern = regexp.MustCompile(`^// Code generated (.*) DO NOT EDIT\.$`)
+
+// header returns the comments of file that precede its package clause, where
+// build constraints and the generated-file marker must appear.
+func header(file *ast.File) []*ast.Comment {
+	var comments []*ast.Comment
+	for _, cg := range file.Comments {
+		if cg.Pos() >= file.Package {
+			break
+		}
+		comments = append(comments, cg.List...)
+	}
+	return comments
+}
+
+func (t *Transformer) extractBuildConstraint(file *ast.File) *model.BuildConstraint {
+	var plusExprs []constraint.Expr
+	var plusPos token.Pos
+	for _, c := range header(file) {
+		switch {
+		case constraint.IsGoBuild(c.Text):
+			if expr, err := constraint.Parse(c.Text); err == nil {
+				return t.buildConstraint(expr, c.Pos())
+			}
+		case constraint.IsPlusBuild(c.Text):
+			if expr, err := constraint.Parse(c.Text); err == nil {
+				if plusExprs == nil {
+					plusPos = c.Pos()
+				}
+				plusExprs = append(plusExprs, expr)
+			}
+		}
+	}
+	if len(plusExprs) == 0 {
+		return nil
+	}
+
+	// Multiple // +build lines must all be satisfied.
+	expr := plusExprs[0]
+	for _, e := range plusExprs[1:] {
+		expr = &constraint.AndExpr{X: expr, Y: e}
+	}
+	return t.buildConstraint(expr, plusPos)
+}
+
+func (t *Transformer) buildConstraint(expr constraint.Expr, pos token.Pos) *model.BuildConstraint {
+	tags := make([]string, 0)
+	expr.Eval(func(tag string) bool {
+		tags = append(tags, tag)
+		return true
+	})
+	slices.Sort(tags)
+
+	return &model.BuildConstraint{
+		Expression: expr.String(),
+		Tags:       slices.Compact(tags),
+		Position:   t.position(pos),
+	}
+}
+
+func (t *Transformer) extractDirectives(file *ast.File) []model.Directive {
+	targets := directiveTargets(file)
+
+	var directives []model.Directive
+	for _, cg := range file.Comments {
+		for _, c := range cg.List {
+			if !strings.HasPrefix(c.Text, "//go:") || constraint.IsGoBuild(c.Text) {
+				continue
+			}
+			name, args, _ := strings.Cut(c.Text[len("//"):], " ")
+			directives = append(directives, model.Directive{
+				Name:     name,
+				Args:     splitArgs(args),
+				Target:   targets[cg],
+				Position: t.position(c.Pos()),
+			})
+		}
+	}
+	return directives
+}
+
+// directiveTargets maps the doc comments of the declarations in file to the
+// names of the functions, types, variables or constants they document.
+func directiveTargets(file *ast.File) map[*ast.CommentGroup]string {
+	targets := make(map[*ast.CommentGroup]string)
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Doc != nil {
+				targets[d.Doc] = d.Name.Name
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				name, doc := specTarget(spec)
+				if doc != nil {
+					targets[doc] = name
+				}
+				if d.Doc != nil && len(d.Specs) == 1 {
+					targets[d.Doc] = name
+				}
+			}
+		}
+	}
+	return targets
+}
+
+func specTarget(spec ast.Spec) (string, *ast.CommentGroup) {
+	switch s := spec.(type) {
+	case *ast.TypeSpec:
+		return s.Name.Name, s.Doc
+	case *ast.ValueSpec:
+		return s.Names[0].Name, s.Doc
+	}
+	return "", nil
+}
+
+// splitArgs splits the arguments of a directive at spaces, treating a
+// double-quoted or back-quoted Go string as a single, unquoted argument as
+// //go:generate and //go:embed do.
+func splitArgs(s string) []string {
+	args := make([]string, 0)
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return args
+		}
+
+		if s[0] == '"' || s[0] == '`' {
+			if quoted, err := strconv.QuotedPrefix(s); err == nil {
+				arg, _ := strconv.Unquote(quoted)
+				args = append(args, arg)
+				s = s[len(quoted):]
+				continue
+			}
+		}
+
+		end := strings.IndexAny(s, " \t")
+		if end < 0 {
+			end = len(s)
+		}
+		args = append(args, s[:end])
+		s = s[end:]
+	}
+}
+
+func (t *Transformer) extractGenerated(file *ast.File) *model.GeneratedMarker {
+	for _, c := range header(file) {
+		if m := generatedPattern.FindStringSubmatch(c.Text); m != nil {
+			generator := strings.TrimSuffix(strings.TrimPrefix(m[1], "by "), ".")
+			return &model.GeneratedMarker{
+				Generator: generator,
+				Position:  t.position(c.Pos()),
+			}
+		}
+	}
+	return nil
+}
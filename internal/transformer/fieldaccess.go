@@ -0,0 +1,150 @@
+package transformer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/burdzwastaken/regolint/internal/model"
+)
+
+// extractFieldAccesses records selector expressions that resolve to struct
+// fields, and fields set by keyed composite literals. It needs type
+// information and returns nothing without it.
+func (t *Transformer) extractFieldAccesses(file *ast.File) []model.FieldAccessInfo {
+	accesses := make([]model.FieldAccessInfo, 0)
+	info := t.pkg.TypesInfo
+	if info == nil {
+		return accesses
+	}
+
+	for _, decl := range file.Decls {
+		var fn string
+		if d, ok := decl.(*ast.FuncDecl); ok {
+			fn = d.Name.Name
+		}
+
+		writes := make(map[ast.Expr]bool)
+		ast.Inspect(decl, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt, *ast.IncDecStmt, *ast.RangeStmt:
+				for _, lhs := range assigned(n) {
+					writes[ast.Unparen(lhs)] = true
+				}
+			case *ast.SelectorExpr:
+				if access, ok := t.selectorAccess(info, n, writes[n]); ok {
+					access.InFunction = fn
+					accesses = append(accesses, access)
+				}
+			case *ast.CompositeLit:
+				for _, access := range t.literalAccesses(info, n) {
+					access.InFunction = fn
+					accesses = append(accesses, access)
+				}
+			}
+			return true
+		})
+	}
+
+	return accesses
+}
+
+// assigned returns the expressions a statement stores to.
+func assigned(stmt ast.Node) []ast.Expr {
+	switch s := stmt.(type) {
+	case *ast.AssignStmt:
+		if s.Tok != token.DEFINE {
+			return s.Lhs
+		}
+	case *ast.IncDecStmt:
+		return []ast.Expr{s.X}
+	case *ast.RangeStmt:
+		if s.Tok == token.ASSIGN {
+			return []ast.Expr{s.Key, s.Value}
+		}
+	}
+	return nil
+}
+
+func (t *Transformer) selectorAccess(info *types.Info, sel *ast.SelectorExpr, write bool) (model.FieldAccessInfo, bool) {
+	selection, ok := info.Selections[sel]
+	if !ok || selection.Kind() != types.FieldVal {
+		return model.FieldAccessInfo{}, false
+	}
+
+	typ, pkg := declaringStruct(selection.Recv(), selection.Index())
+	return model.FieldAccessInfo{
+		Field:    sel.Sel.Name,
+		Receiver: t.formatExpr(sel.X),
+		Type:     typ,
+		Package:  pkg,
+		IsWrite:  write,
+		Position: t.position(sel.Sel.Pos()),
+	}, true
+}
+
+// literalAccesses records the fields set by a keyed struct literal as writes.
+func (t *Transformer) literalAccesses(info *types.Info, lit *ast.CompositeLit) []model.FieldAccessInfo {
+	tv, ok := info.Types[lit]
+	if !ok {
+		return nil
+	}
+	if _, isStruct := deref(tv.Type).Underlying().(*types.Struct); !isStruct {
+		return nil
+	}
+	typ, pkg := typeName(deref(tv.Type))
+
+	var accesses []model.FieldAccessInfo
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if key, ok := kv.Key.(*ast.Ident); ok {
+			accesses = append(accesses, model.FieldAccessInfo{
+				Field:    key.Name,
+				Receiver: t.formatExpr(lit),
+				Type:     typ,
+				Package:  pkg,
+				IsWrite:  true,
+				Position: t.position(key.Pos()),
+			})
+		}
+	}
+	return accesses
+}
+
+// declaringStruct follows the embedding path index from recv to the struct
+// type that declares the selected field.
+func declaringStruct(recv types.Type, index []int) (string, string) {
+	typ := deref(recv)
+	for _, i := range index[:len(index)-1] {
+		st, ok := typ.Underlying().(*types.Struct)
+		if !ok {
+			break
+		}
+		typ = deref(st.Field(i).Type())
+	}
+	return typeName(typ)
+}
+
+// typeName returns the name and package path of a named type, or the type
+// string of an unnamed one.
+func typeName(typ types.Type) (string, string) {
+	switch t := types.Unalias(typ).(type) {
+	case *types.Named:
+		if t.Obj().Pkg() == nil {
+			return t.Obj().Name(), ""
+		}
+		return t.Obj().Name(), t.Obj().Pkg().Path()
+	default:
+		return types.TypeString(typ, nil), ""
+	}
+}
+
+func deref(typ types.Type) types.Type {
+	if p, ok := types.Unalias(typ).(*types.Pointer); ok {
+		return p.Elem()
+	}
+	return typ
+}
@@ -3,8 +3,10 @@ package transformer
 import (
 	"go/ast"
 	"go/token"
+	"go/types"
 	"strings"
 
+	"github.com/burdzwastaken/regolint/internal/callgraph"
 	"github.com/burdzwastaken/regolint/internal/model"
 )
 
@@ -22,6 +24,9 @@ func (t *Transformer) extractFunction(fn *ast.FuncDecl) model.FunctionInfo {
 		info.Receiver = t.formatReceiver(fn.Recv.List[0])
 	}
 
+	info.TypeParams = t.extractTypeParams(fn.Type.TypeParams)
+	t.linkCalls(fn, &info)
+
 	if fn.Type.Params != nil {
 		info.Parameters = t.extractParams(fn.Type.Params)
 	}
@@ -46,19 +51,39 @@ func (t *Transformer) extractFunction(fn *ast.FuncDecl) model.FunctionInfo {
 	return info
 }
 
+// linkCalls sets the qualified name of fn and its neighbours in the call
+// graph, if any. It needs type information.
+func (t *Transformer) linkCalls(fn *ast.FuncDecl, info *model.FunctionInfo) {
+	if t.pkg.TypesInfo == nil {
+		return
+	}
+	obj, ok := t.pkg.TypesInfo.Defs[fn.Name].(*types.Func)
+	if !ok {
+		return
+	}
+
+	info.QualifiedName = callgraph.Name(obj)
+	if t.callGraph != nil {
+		info.Callees = t.callGraph.Callees(info.QualifiedName)
+		info.Callers = t.callGraph.Callers(info.QualifiedName)
+	}
+}
+
 func (t *Transformer) extractParams(fields *ast.FieldList) []model.ParameterInfo {
 	params := make([]model.ParameterInfo, 0)
 
 	for _, field := range fields.List {
-		typeStr := t.formatType(field.Type)
+		typeStr := t.typeString(field.Type)
+		detail := typeDetail(t.typeOf(field.Type))
 
 		if len(field.Names) == 0 {
-			params = append(params, model.ParameterInfo{Type: typeStr})
+			params = append(params, model.ParameterInfo{Type: typeStr, TypeInfo: detail})
 		} else {
 			for _, name := range field.Names {
 				params = append(params, model.ParameterInfo{
-					Name: name.Name,
-					Type: typeStr,
+					Name:     name.Name,
+					Type:     typeStr,
+					TypeInfo: detail,
 				})
 			}
 		}
@@ -67,10 +92,114 @@ func (t *Transformer) extractParams(fields *ast.FieldList) []model.ParameterInfo
 	return params
 }
 
+// extractTypeParams returns the type parameters in fields with their
+// constraints, resolved when type information is available.
+func (t *Transformer) extractTypeParams(fields *ast.FieldList) []model.TypeParamInfo {
+	if fields == nil {
+		return nil
+	}
+
+	params := make([]model.TypeParamInfo, 0, fields.NumFields())
+	for _, field := range fields.List {
+		constraint := t.formatType(field.Type)
+		for _, name := range field.Names {
+			param := model.TypeParamInfo{Name: name.Name, Constraint: constraint}
+			if t.pkg.TypesInfo != nil {
+				if obj, ok := t.pkg.TypesInfo.Defs[name].(*types.TypeName); ok {
+					if tp, ok := obj.Type().(*types.TypeParam); ok {
+						param.Constraint = types.TypeString(tp.Constraint(), t.qualifier)
+					}
+				}
+			}
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
 func (t *Transformer) formatReceiver(field *ast.Field) string {
-	return t.formatType(field.Type)
+	return t.typeString(field.Type)
+}
+
+// typeString renders the type expression expr. With type information it is
+// rendered by go/types, so that aliased imports and type aliases read the
+// same everywhere, and otherwise as written.
+func (t *Transformer) typeString(expr ast.Expr) string {
+	if typ := t.typeOf(expr); typ != nil {
+		if e, ok := expr.(*ast.Ellipsis); ok {
+			return "..." + t.typeString(e.Elt)
+		}
+		return types.TypeString(typ, t.qualifier)
+	}
+	return t.formatType(expr)
 }
 
+// typeOf returns the type denoted by the type expression expr, treating a
+// variadic parameter as a slice, or nil without type information.
+func (t *Transformer) typeOf(expr ast.Expr) types.Type {
+	if t.pkg.TypesInfo == nil {
+		return nil
+	}
+	if e, ok := expr.(*ast.Ellipsis); ok {
+		if elem := t.pkg.TypesInfo.TypeOf(e.Elt); elem != nil {
+			return types.NewSlice(elem)
+		}
+		return nil
+	}
+	return t.pkg.TypesInfo.TypeOf(expr)
+}
+
+// formatSignature renders the parameters and results of fn as written.
+func (t *Transformer) formatSignature(fn *ast.FuncType) string {
+	sig := "(" + t.formatFields(fn.Params, ", ") + ")"
+	if fn.Results == nil || len(fn.Results.List) == 0 {
+		return sig
+	}
+	results := t.formatFields(fn.Results, ", ")
+	if len(fn.Results.List) == 1 && len(fn.Results.List[0].Names) == 0 {
+		return sig + " " + results
+	}
+	return sig + " (" + results + ")"
+}
+
+// formatInterface renders the methods and embedded types of iface as
+// written.
+func (t *Transformer) formatInterface(iface *ast.InterfaceType) string {
+	parts := make([]string, 0, len(iface.Methods.List))
+	for _, field := range iface.Methods.List {
+		if fn, ok := field.Type.(*ast.FuncType); ok && len(field.Names) == 1 {
+			parts = append(parts, field.Names[0].Name+t.formatSignature(fn))
+			continue
+		}
+		parts = append(parts, t.formatType(field.Type))
+	}
+	return "interface{" + strings.Join(parts, "; ") + "}"
+}
+
+// formatFields renders a parameter list or struct fields as written, joined
+// by sep.
+func (t *Transformer) formatFields(fields *ast.FieldList, sep string) string {
+	if fields == nil {
+		return ""
+	}
+
+	parts := make([]string, 0, len(fields.List))
+	for _, field := range fields.List {
+		names := make([]string, len(field.Names))
+		for i, name := range field.Names {
+			names[i] = name.Name
+		}
+
+		part := strings.TrimSpace(strings.Join(names, ", ") + " " + t.formatType(field.Type))
+		if field.Tag != nil {
+			part += " " + field.Tag.Value
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, sep)
+}
+
+// nolint:PKG002 // one case per AST type expression
 func (t *Transformer) formatType(expr ast.Expr) string {
 	switch e := expr.(type) {
 	case *ast.Ident:
@@ -80,10 +209,14 @@ func (t *Transformer) formatType(expr ast.Expr) string {
 	case *ast.SelectorExpr:
 		return t.formatType(e.X) + "." + e.Sel.Name
 	case *ast.ArrayType:
-		if e.Len == nil {
+		switch e.Len.(type) {
+		case nil:
 			return "[]" + t.formatType(e.Elt)
+		case *ast.Ellipsis:
+			return "[...]" + t.formatType(e.Elt)
+		default:
+			return "[" + t.formatExpr(e.Len) + "]" + t.formatType(e.Elt)
 		}
-		return "[...]" + t.formatType(e.Elt)
 	case *ast.MapType:
 		return "map[" + t.formatType(e.Key) + "]" + t.formatType(e.Value)
 	case *ast.ChanType:
@@ -96,13 +229,29 @@ func (t *Transformer) formatType(expr ast.Expr) string {
 			return "chan " + t.formatType(e.Value)
 		}
 	case *ast.FuncType:
-		return "func(...)"
+		return "func" + t.formatSignature(e)
 	case *ast.InterfaceType:
-		return "interface{}"
+		return t.formatInterface(e)
 	case *ast.StructType:
-		return "struct{}"
+		return "struct{" + t.formatFields(e.Fields, "; ") + "}"
 	case *ast.Ellipsis:
 		return "..." + t.formatType(e.Elt)
+	case *ast.IndexExpr:
+		return t.formatType(e.X) + "[" + t.formatType(e.Index) + "]"
+	case *ast.IndexListExpr:
+		args := make([]string, len(e.Indices))
+		for i, index := range e.Indices {
+			args[i] = t.formatType(index)
+		}
+		return t.formatType(e.X) + "[" + strings.Join(args, ", ") + "]"
+	case *ast.UnaryExpr:
+		// ~T in a constraint.
+		return e.Op.String() + t.formatType(e.X)
+	case *ast.BinaryExpr:
+		// A union in a constraint.
+		return t.formatType(e.X) + " " + e.Op.String() + " " + t.formatType(e.Y)
+	case *ast.ParenExpr:
+		return "(" + t.formatType(e.X) + ")"
 	default:
 		return "unknown"
 	}
@@ -0,0 +1,178 @@
+package transformer
+
+import (
+	"go/ast"
+	"go/types"
+	"slices"
+	"strings"
+
+	"github.com/burdzwastaken/regolint/internal/callgraph"
+	"github.com/burdzwastaken/regolint/internal/model"
+)
+
+// Option configures a Transformer.
+type Option func(*Transformer)
+
+// WithInterfaces sets the well-known interfaces, such as io.Reader or
+// encoding/json.Marshaler, that types are checked against in addition to the
+// interfaces of their own package and its direct imports.
+func WithInterfaces(names []string) Option {
+	return func(t *Transformer) {
+		t.wellKnown = names
+	}
+}
+
+// WithCallGraph sets the call graph that the callees and callers of each
+// function are read from.
+func WithCallGraph(g *callgraph.Graph) Option {
+	return func(t *Transformer) {
+		t.callGraph = g
+	}
+}
+
+// WithTaintFlows sets the taint flows of the whole program. Each file is
+// given those whose sink is in it.
+func WithTaintFlows(flows []model.TaintFlow) Option {
+	return func(t *Transformer) {
+		t.flows = flows
+	}
+}
+
+// taintFlows returns the taint flows whose sink is in the file at filePath.
+func (t *Transformer) taintFlows(filePath string) []model.TaintFlow {
+	var flows []model.TaintFlow
+	for _, flow := range t.flows {
+		if flow.Sink.Position.File == filePath {
+			flows = append(flows, flow)
+		}
+	}
+	return flows
+}
+
+// extractImplements returns the interfaces the type declared by spec
+// implements, and separately those only its pointer type implements. It needs
+// type information and skips generic types.
+func (t *Transformer) extractImplements(spec *ast.TypeSpec) ([]string, []string) {
+	if t.pkg.TypesInfo == nil || spec.TypeParams != nil {
+		return nil, nil
+	}
+	obj, ok := t.pkg.TypesInfo.Defs[spec.Name].(*types.TypeName)
+	if !ok {
+		return nil, nil
+	}
+
+	typ := obj.Type()
+	var implements, pointer []string
+	for _, iface := range t.interfaceCandidates() {
+		if iface.Obj() == obj {
+			continue
+		}
+		switch {
+		case types.Implements(typ, iface.Underlying().(*types.Interface)):
+			implements = append(implements, types.TypeString(iface, t.qualifier))
+		case !types.IsInterface(typ) && types.Implements(types.NewPointer(typ), iface.Underlying().(*types.Interface)):
+			pointer = append(pointer, types.TypeString(iface, t.qualifier))
+		}
+	}
+
+	slices.Sort(implements)
+	slices.Sort(pointer)
+	return implements, pointer
+}
+
+// interfaceCandidates collects, once per Transformer, the interfaces with
+// methods declared in the package, its direct imports and the well-known
+// list.
+func (t *Transformer) interfaceCandidates() []*types.Named {
+	t.interfacesOnce.Do(func() {
+		seen := make(map[*types.TypeName]bool)
+		add := func(obj types.Object) {
+			tn, ok := obj.(*types.TypeName)
+			if !ok || seen[tn] {
+				return
+			}
+			if iface := methodInterface(tn); iface != nil {
+				seen[tn] = true
+				t.interfaces = append(t.interfaces, iface)
+			}
+		}
+
+		for _, name := range t.pkg.Pkg.Scope().Names() {
+			add(t.pkg.Pkg.Scope().Lookup(name))
+		}
+		for _, imp := range t.pkg.Pkg.Imports() {
+			for _, name := range imp.Scope().Names() {
+				if obj := imp.Scope().Lookup(name); obj.Exported() {
+					add(obj)
+				}
+			}
+		}
+		for _, name := range t.wellKnown {
+			if obj := lookupInterface(t.pkg.Pkg, name); obj != nil {
+				add(obj)
+			} else {
+				t.unresolved = append(t.unresolved, name)
+			}
+		}
+	})
+	return t.interfaces
+}
+
+// methodInterface returns the named type of tn if it is a non-generic
+// interface with at least one method that is not a type constraint.
+func methodInterface(tn *types.TypeName) *types.Named {
+	named, ok := tn.Type().(*types.Named)
+	if !ok || named.TypeParams() != nil {
+		return nil
+	}
+	iface, ok := named.Underlying().(*types.Interface)
+	if !ok || !iface.IsMethodSet() || iface.NumMethods() == 0 {
+		return nil
+	}
+	return named
+}
+
+// UnresolvedInterfaces returns the well-known interfaces that are neither
+// declared in the analyzed package nor in any package it imports, directly or
+// not. Types are not checked against them.
+func (t *Transformer) UnresolvedInterfaces() []string {
+	if t.pkg.Pkg == nil {
+		return nil
+	}
+	t.interfaceCandidates()
+	return t.unresolved
+}
+
+// lookupInterface resolves a well-known interface name such as io.Reader in
+// pkg or its transitive imports. It returns nil when pkg does not depend on
+// the package declaring the interface.
+func lookupInterface(pkg *types.Package, name string) types.Object {
+	dot := strings.LastIndex(name, ".")
+	if dot < 0 {
+		return types.Universe.Lookup(name)
+	}
+	path, typeName := name[:dot], name[dot+1:]
+
+	if dep := findPackage(pkg, path, make(map[*types.Package]bool)); dep != nil {
+		return dep.Scope().Lookup(typeName)
+	}
+	return nil
+}
+
+// findPackage returns pkg or the transitive import of pkg with path.
+func findPackage(pkg *types.Package, path string, visited map[*types.Package]bool) *types.Package {
+	if pkg == nil || visited[pkg] {
+		return nil
+	}
+	visited[pkg] = true
+
+	if pkg.Path() == path {
+		return pkg
+	}
+	for _, imp := range pkg.Imports() {
+		if found := findPackage(imp, path, visited); found != nil {
+			return found
+		}
+	}
+	return nil
+}
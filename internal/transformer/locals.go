@@ -0,0 +1,148 @@
+package transformer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/burdzwastaken/regolint/internal/model"
+)
+
+// recordLocals records the local declarations and assignments made by n.
+// Package-level declarations are extracted by extractGenDecl instead.
+func (t *Transformer) recordLocals(n ast.Node, s funcScope, out *bodyInfo) {
+	if s.function == "" {
+		return
+	}
+
+	switch n := n.(type) {
+	case *ast.AssignStmt:
+		for i, lhs := range n.Lhs {
+			t.recordAssignment(lhs, n.Tok, n.Rhs, i, s, out)
+		}
+	case *ast.DeclStmt:
+		t.recordDecl(n.Decl.(*ast.GenDecl), s, out)
+	case *ast.RangeStmt:
+		if n.Tok == token.ILLEGAL {
+			return
+		}
+		for _, lhs := range []ast.Expr{n.Key, n.Value} {
+			if lhs != nil {
+				t.appendAssignment(lhs, n.Tok, "range "+t.formatExpr(n.X), t.targetType(lhs), 0, s, out)
+			}
+		}
+	}
+}
+
+// recordDecl records the variables and constants declared by a local var or
+// const declaration, and the assignments of their initial values.
+func (t *Transformer) recordDecl(decl *ast.GenDecl, s funcScope, out *bodyInfo) {
+	if decl.Tok != token.VAR && decl.Tok != token.CONST {
+		return
+	}
+
+	for _, spec := range decl.Specs {
+		spec := spec.(*ast.ValueSpec)
+		for _, v := range t.extractVariables(spec, decl.Tok == token.CONST) {
+			if v.Name == "_" {
+				continue
+			}
+			v.IsExported = false
+			v.InFunction = s.function
+			if v.IsConst {
+				out.constants = append(out.constants, v)
+			} else {
+				out.variables = append(out.variables, v)
+			}
+		}
+		if decl.Tok == token.VAR && len(spec.Values) > 0 {
+			for i, name := range spec.Names {
+				t.recordAssignment(name, token.VAR, spec.Values, i, s, out)
+			}
+		}
+	}
+}
+
+// recordAssignment records the assignment of the i-th value of rhs to lhs. A
+// single value assigned to several targets is a tuple, such as the results
+// of a call, and is recorded once per target with its index.
+func (t *Transformer) recordAssignment(lhs ast.Expr, tok token.Token, rhs []ast.Expr, i int, s funcScope, out *bodyInfo) {
+	value, index := rhs[0], i
+	if len(rhs) > 1 {
+		value, index = rhs[i], 0
+	}
+
+	typ := t.targetType(lhs)
+	if typ == "" {
+		typ = t.valueType(value, index)
+	}
+	t.appendAssignment(lhs, tok, t.formatExpr(value), typ, index, s, out)
+}
+
+// appendAssignment adds an assignment of value to lhs. Targets newly defined
+// by := are also recorded as local variables.
+func (t *Transformer) appendAssignment(lhs ast.Expr, tok token.Token, value, typ string, index int, s funcScope, out *bodyInfo) {
+	ident, _ := ast.Unparen(lhs).(*ast.Ident)
+	discarded := ident != nil && ident.Name == "_"
+
+	out.assignments = append(out.assignments, model.AssignmentInfo{
+		Target:      t.formatExpr(lhs),
+		Type:        typ,
+		IsDiscarded: discarded,
+		Value:       value,
+		ValueIndex:  index,
+		Op:          tok.String(),
+		InFunction:  s.function,
+		Position:    t.position(lhs.Pos()),
+	})
+
+	if tok == token.DEFINE && ident != nil && !discarded && t.defines(ident) {
+		out.variables = append(out.variables, model.VariableInfo{
+			Name:       ident.Name,
+			Type:       typ,
+			Value:      value,
+			InFunction: s.function,
+			Position:   t.position(ident.Pos()),
+		})
+	}
+}
+
+// defines reports whether ident declares a new variable. Without type
+// information, every identifier on the left of := is assumed to.
+func (t *Transformer) defines(ident *ast.Ident) bool {
+	if t.pkg.TypesInfo == nil {
+		return true
+	}
+	return t.pkg.TypesInfo.Defs[ident] != nil
+}
+
+// targetType returns the type of an assignment target or declared name, or ""
+// for the blank identifier and without type information.
+func (t *Transformer) targetType(lhs ast.Expr) string {
+	if t.pkg.TypesInfo == nil {
+		return ""
+	}
+	if typ := t.pkg.TypesInfo.TypeOf(lhs); typ != nil {
+		return types.TypeString(typ, t.qualifier)
+	}
+	return ""
+}
+
+// valueType returns the type of value, or of its index-th element when it
+// is a tuple such as the results of a call or a comma-ok expression.
+func (t *Transformer) valueType(value ast.Expr, index int) string {
+	if t.pkg.TypesInfo == nil {
+		return ""
+	}
+	typ := t.pkg.TypesInfo.TypeOf(value)
+	if tuple, ok := typ.(*types.Tuple); ok {
+		if index >= tuple.Len() {
+			return ""
+		}
+		typ = tuple.At(index).Type()
+	}
+	if typ == nil {
+		return ""
+	}
+	return types.TypeString(typ, t.qualifier)
+}
@@ -0,0 +1,135 @@
+package transformer
+
+import (
+	"cmp"
+	"go/ast"
+	"go/types"
+	"slices"
+	"strings"
+
+	"github.com/burdzwastaken/regolint/internal/model"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// extractMethods returns the methods declared on the type of spec, in any
+// file of the package, and the method set of the type and its pointer
+// including methods promoted from embedded fields. It needs type information.
+func (t *Transformer) extractMethods(spec *ast.TypeSpec) ([]model.MethodInfo, []model.MethodInfo) {
+	if t.pkg.TypesInfo == nil {
+		return nil, nil
+	}
+	obj, ok := t.pkg.TypesInfo.Defs[spec.Name].(*types.TypeName)
+	if !ok {
+		return nil, nil
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, nil
+	}
+
+	declared := make([]model.MethodInfo, 0, named.NumMethods())
+	for fn := range named.Methods() {
+		declared = append(declared, t.methodInfo(fn))
+	}
+
+	var set []model.MethodInfo
+	for _, sel := range typeutil.IntuitiveMethodSet(named, nil) {
+		method := t.methodInfo(sel.Obj().(*types.Func))
+		if len(sel.Index()) > 1 {
+			method.PromotedFrom = types.TypeString(receiverBase(sel.Obj().(*types.Func)), t.qualifier)
+		}
+		set = append(set, method)
+	}
+
+	return declared, set
+}
+
+func (t *Transformer) methodInfo(fn *types.Func) model.MethodInfo {
+	sig := fn.Signature()
+	_, pointer := sig.Recv().Type().(*types.Pointer)
+	return model.MethodInfo{
+		Name:            fn.Name(),
+		Parameters:      t.tupleParams(sig.Params(), sig.Variadic()),
+		Returns:         t.tupleParams(sig.Results(), false),
+		IsExported:      fn.Exported(),
+		PointerReceiver: pointer,
+		Position:        t.position(fn.Pos()),
+	}
+}
+
+// tupleParams converts a parameter or result list. The last parameter of a
+// variadic signature is written ...T, as in source.
+func (t *Transformer) tupleParams(tuple *types.Tuple, variadic bool) []model.ParameterInfo {
+	params := make([]model.ParameterInfo, 0, tuple.Len())
+	for i := range tuple.Len() {
+		v := tuple.At(i)
+		typ := types.TypeString(v.Type(), t.qualifier)
+		if slice, ok := v.Type().(*types.Slice); ok && variadic && i == tuple.Len()-1 {
+			typ = "..." + types.TypeString(slice.Elem(), t.qualifier)
+		}
+		params = append(params, model.ParameterInfo{Name: v.Name(), Type: typ, TypeInfo: typeDetail(v.Type())})
+	}
+	return params
+}
+
+// qualifier names packages other than the one being analyzed by their
+// package name, as they are usually written in source.
+func (t *Transformer) qualifier(pkg *types.Package) string {
+	if pkg == t.pkg.Pkg {
+		return ""
+	}
+	return pkg.Name()
+}
+
+// receiverBase returns the receiver type of a method without its pointer.
+func receiverBase(fn *types.Func) types.Type {
+	typ := fn.Signature().Recv().Type()
+	if p, ok := typ.(*types.Pointer); ok {
+		return p.Elem()
+	}
+	return typ
+}
+
+// attachMethods adds the methods declared on each type in the same file,
+// for use when type information is unavailable.
+func attachMethods(ctx *model.CodeContext) {
+	for i := range ctx.Types {
+		if ctx.Types[i].Kind != "interface" {
+			ctx.Types[i].Methods = appendMethods(ctx.Types[i].Methods, ctx.Types[i].Name, ctx.Functions)
+		}
+	}
+}
+
+// appendMethods appends the functions with a receiver of type typeName that
+// are not in methods yet, ordered by position.
+func appendMethods(methods []model.MethodInfo, typeName string, functions []model.FunctionInfo) []model.MethodInfo {
+	methods = slices.Clip(methods)
+	for _, fn := range functions {
+		if receiverName(fn.Receiver) != typeName {
+			continue
+		}
+		if slices.ContainsFunc(methods, func(m model.MethodInfo) bool { return m.Name == fn.Name }) {
+			continue
+		}
+		methods = append(methods, model.MethodInfo{
+			Name:            fn.Name,
+			Parameters:      fn.Parameters,
+			Returns:         fn.Returns,
+			IsExported:      fn.IsExported,
+			PointerReceiver: strings.HasPrefix(fn.Receiver, "*"),
+			Position:        fn.Position,
+		})
+	}
+
+	slices.SortStableFunc(methods, func(a, b model.MethodInfo) int {
+		return cmp.Or(cmp.Compare(a.Position.File, b.Position.File), cmp.Compare(a.Position.Line, b.Position.Line))
+	})
+	return methods
+}
+
+// receiverName strips the pointer and type arguments from a receiver type.
+func receiverName(receiver string) string {
+	name := strings.TrimPrefix(receiver, "*")
+	name, _, _ = strings.Cut(name, "[")
+	return name
+}
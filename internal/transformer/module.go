@@ -0,0 +1,109 @@
+package transformer
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/burdzwastaken/regolint/internal/model"
+)
+
+// BuildModuleContext summarizes the packages of a module, each given as the
+// CodeContexts of its files, and collects the imports between them. An
+// import is part of the module graph when its path lies under modulePath or
+// names one of the given packages.
+func BuildModuleContext(modulePath string, packages [][]*model.CodeContext) *model.ModuleContext {
+	mod := &model.ModuleContext{
+		ModulePath: modulePath,
+		Packages:   make([]model.PackageSummary, 0, len(packages)),
+		Imports:    make([]model.ImportEdge, 0),
+	}
+
+	analyzed := make(map[string]bool, len(packages))
+	for _, files := range packages {
+		if len(files) > 0 {
+			analyzed[files[0].Package.Path] = true
+		}
+	}
+	inModule := func(path string) bool {
+		return analyzed[path] || modulePath != "" && (path == modulePath || strings.HasPrefix(path, modulePath+"/"))
+	}
+
+	for _, files := range packages {
+		if len(files) == 0 {
+			continue
+		}
+		mod.Packages = append(mod.Packages, summarize(files))
+		for _, f := range files {
+			for _, imp := range f.Imports {
+				if inModule(imp.Path) {
+					mod.Imports = append(mod.Imports, model.ImportEdge{
+						From:     f.Package.Path,
+						To:       imp.Path,
+						Position: withPath(imp.Position, f.FilePath),
+					})
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(mod.Packages, func(a, b model.PackageSummary) int { return cmp.Compare(a.Path, b.Path) })
+	return mod
+}
+
+// summarize describes the package whose files are given.
+func summarize(files []*model.CodeContext) model.PackageSummary {
+	first := files[0]
+	summary := model.PackageSummary{
+		Path:      first.Package.Path,
+		Name:      first.Package.Name,
+		Files:     make([]string, 0, len(files)),
+		Imports:   make([]string, 0),
+		Types:     make([]model.SymbolInfo, 0),
+		Functions: make([]model.SymbolInfo, 0),
+		Position:  withPath(first.Package.Position, first.FilePath),
+	}
+
+	for _, f := range files {
+		summary.Files = append(summary.Files, f.FilePath)
+		summary.Doc = cmp.Or(summary.Doc, f.Package.Doc)
+		for _, imp := range f.Imports {
+			summary.Imports = append(summary.Imports, imp.Path)
+		}
+		for _, typ := range f.Types {
+			summary.Types = append(summary.Types, model.SymbolInfo{
+				Name:       typ.Name,
+				Kind:       typ.Kind,
+				IsExported: typ.IsExported,
+				Position:   withPath(typ.Position, f.FilePath),
+			})
+		}
+		for _, fn := range f.Functions {
+			kind := "function"
+			if fn.Receiver != "" {
+				kind = "method"
+			}
+			summary.Functions = append(summary.Functions, model.SymbolInfo{
+				Name:          fn.Name,
+				QualifiedName: fn.QualifiedName,
+				Kind:          kind,
+				Receiver:      fn.Receiver,
+				IsExported:    fn.IsExported,
+				Position:      withPath(fn.Position, f.FilePath),
+			})
+		}
+	}
+
+	slices.Sort(summary.Imports)
+	summary.Imports = slices.Compact(summary.Imports)
+	return summary
+}
+
+// withPath names the file of pos by path instead of its base name.
+func withPath(pos model.Position, path string) model.Position {
+	if pos.File == filepath.Base(path) {
+		pos.File = path
+	}
+	return pos
+}
@@ -39,5 +39,13 @@ func BuildPackageContext(files []*model.CodeContext) *model.PackageContext {
 		pkg.AllCalls = append(pkg.AllCalls, f.Calls...)
 	}
 
+	// Without type information, each file only knows the methods declared in
+	// it, so gather the methods declared elsewhere in the package.
+	for i := range pkg.AllTypes {
+		if pkg.AllTypes[i].Kind != "interface" {
+			pkg.AllTypes[i].Methods = appendMethods(pkg.AllTypes[i].Methods, pkg.AllTypes[i].Name, pkg.AllFunctions)
+		}
+	}
+
 	return pkg
 }
@@ -3,9 +3,12 @@ package transformer
 import (
 	"go/ast"
 	"go/token"
+	"go/types"
 	"path/filepath"
 	"strings"
+	"sync"
 
+	"github.com/burdzwastaken/regolint/internal/callgraph"
 	"github.com/burdzwastaken/regolint/internal/model"
 	"github.com/burdzwastaken/regolint/internal/nolint"
 	"golang.org/x/tools/go/analysis"
@@ -16,15 +19,26 @@ type Transformer struct {
 	fset       *token.FileSet
 	pkg        *analysis.Pass
 	modulePath string
+	wellKnown  []string
+	callGraph  *callgraph.Graph
+	flows      []model.TaintFlow
+
+	interfacesOnce sync.Once
+	interfaces     []*types.Named
+	unresolved     []string
 }
 
 // New creates a new Transformer.
-func New(pass *analysis.Pass, modulePath string) *Transformer {
-	return &Transformer{
+func New(pass *analysis.Pass, modulePath string, opts ...Option) *Transformer {
+	t := &Transformer{
 		fset:       pass.Fset,
 		pkg:        pass,
 		modulePath: modulePath,
 	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
 }
 
 // Transform converts an AST file to CodeContext.
@@ -33,39 +47,60 @@ func (t *Transformer) Transform(file *ast.File, filePath string) *model.CodeCont
 		FilePath:   filePath,
 		ModulePath: t.modulePath,
 		Package: model.PackageInfo{
-			Name: file.Name.Name,
-			Path: t.pkg.Pkg.Path(),
-			Doc:  extractDoc(file.Doc),
+			Name:     file.Name.Name,
+			Path:     t.pkg.Pkg.Path(),
+			Doc:      extractDoc(file.Doc),
+			Position: t.position(file.Package),
 		},
-		Imports:     make([]model.ImportInfo, 0),
+		Imports:     t.extractImports(file),
 		Functions:   make([]model.FunctionInfo, 0),
 		Types:       make([]model.TypeInfo, 0),
 		Variables:   make([]model.VariableInfo, 0),
 		Constants:   make([]model.VariableInfo, 0),
-		Calls:       make([]model.CallInfo, 0),
-		TypeUsages:  make([]model.TypeUsageInfo, 0),
-		FieldAccess: make([]model.FieldAccessInfo, 0),
-	}
+		TypeUsages:  t.extractTypeUsages(file),
+		FieldAccess: t.extractFieldAccesses(file),
+		TaintFlows:  t.taintFlows(filePath),
+		Nolints:     t.extractNolints(file),
 
-	ctx.Imports = t.extractImports(file)
-	ctx.Nolints = t.extractNolints(file)
+		BuildConstraint: t.extractBuildConstraint(file),
+		Directives:      t.extractDirectives(file),
+		Generated:       t.extractGenerated(file),
+	}
 
-	ast.Inspect(file, func(n ast.Node) bool {
-		switch node := n.(type) {
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
 		case *ast.FuncDecl:
-			fn := t.extractFunction(node)
-			ctx.Functions = append(ctx.Functions, fn)
-			calls := t.extractCalls(node, fn.Name)
-			ctx.Calls = append(ctx.Calls, calls...)
+			ctx.Functions = append(ctx.Functions, t.extractFunction(d))
 		case *ast.GenDecl:
-			t.extractGenDecl(node, ctx)
+			t.extractGenDecl(d, ctx)
 		}
-		return true
-	})
+		t.extractLocalTypes(decl, ctx)
+	}
+
+	body := t.walkBodies(file)
+	ctx.Calls = body.calls
+	ctx.Variables = append(ctx.Variables, body.variables...)
+	ctx.Constants = append(ctx.Constants, body.constants...)
+	ctx.Assignments = body.assignments
+
+	if t.pkg.TypesInfo == nil {
+		attachMethods(ctx)
+	}
 
 	return ctx
 }
 
+// extractLocalTypes adds the types declared inside decl. Local variables and
+// constants come from walkBodies.
+func (t *Transformer) extractLocalTypes(decl ast.Decl, ctx *model.CodeContext) {
+	ast.Inspect(decl, func(n ast.Node) bool {
+		if gen, ok := n.(*ast.GenDecl); ok && gen != decl && gen.Tok == token.TYPE {
+			t.extractGenDecl(gen, ctx)
+		}
+		return true
+	})
+}
+
 func (t *Transformer) position(pos token.Pos) model.Position {
 	p := t.fset.Position(pos)
 	return model.Position{
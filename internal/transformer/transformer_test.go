@@ -1,8 +1,13 @@
 package transformer_test
 
 import (
+	"go/ast"
+	"go/importer"
 	"go/parser"
 	"go/token"
+	"go/types"
+	"maps"
+	"slices"
 	"testing"
 
 	"github.com/burdzwastaken/regolint/internal/model"
@@ -164,6 +169,115 @@ type ID = string
 	}
 }
 
+func TestTransformStructTags(t *testing.T) {
+	src := `package example
+
+type User struct {
+	ID    int    ` + "`json:\"id,omitempty\" db:\"user_id\"`" + `
+	Name  string "json:\"name\""
+	Email string ` + "`json:\"email\"xml:\"email\"`" + `
+	Age   int
+}
+`
+	ctx := transformSource(t, src)
+	if len(ctx.Types) != 1 || len(ctx.Types[0].Fields) != 4 {
+		t.Fatalf("expected one struct with 4 fields, got %+v", ctx.Types)
+	}
+	fields := ctx.Types[0].Fields
+
+	id := fields[0].TagMap["json"]
+	if id.Name != "id" || !slices.Equal(id.Options, []string{"omitempty"}) || fields[0].TagMap["db"].Name != "user_id" {
+		t.Errorf("ID tag map = %+v", fields[0].TagMap)
+	}
+	if fields[1].Tags != `json:"name"` || fields[1].TagMap["json"].Name != "name" {
+		t.Errorf("interpreted string tag = %q, %+v", fields[1].Tags, fields[1].TagMap)
+	}
+	if !slices.Equal(fields[2].TagErrors, []string{`key:"value" pairs not separated by spaces`}) {
+		t.Errorf("Email tag errors = %q", fields[2].TagErrors)
+	}
+	if _, ok := fields[2].TagMap["xml"]; ok || fields[2].TagMap["json"].Name != "email" {
+		t.Errorf("Email tag map = %+v, want only json", fields[2].TagMap)
+	}
+	if fields[3].TagMap != nil || fields[3].TagErrors != nil {
+		t.Errorf("expected no tag map for an untagged field, got %+v", fields[3])
+	}
+}
+
+func TestTransformDirectives(t *testing.T) {
+	src := `// Code generated by mockgen. DO NOT EDIT.
+
+//go:build linux && (amd64 || arm64)
+
+package example
+
+import (
+	_ "embed"
+	_ "unsafe"
+)
+
+//go:generate mockgen -destination "mock store.go" -package example . Store
+
+//go:embed ` + "`static files/*`" + ` templates
+var assets string
+
+// nanotime returns the runtime clock.
+//
+//go:linkname nanotime runtime.nanotime
+func nanotime() int64
+
+//go:noinline
+func hot() {}
+`
+	ctx := transformSource(t, src)
+
+	bc := ctx.BuildConstraint
+	if bc == nil || bc.Expression != "linux && (amd64 || arm64)" || !slices.Equal(bc.Tags, []string{"amd64", "arm64", "linux"}) || bc.Position.Line != 3 {
+		t.Errorf("build constraint = %+v", bc)
+	}
+	if g := ctx.Generated; g == nil || g.Generator != "mockgen" || g.Position.Line != 1 {
+		t.Errorf("generated = %+v", g)
+	}
+
+	type directive struct {
+		name, target string
+		args         []string
+		line         int
+	}
+	want := []directive{
+		{"go:generate", "", []string{"mockgen", "-destination", "mock store.go", "-package", "example", ".", "Store"}, 12},
+		{"go:embed", "assets", []string{"static files/*", "templates"}, 14},
+		{"go:linkname", "nanotime", []string{"nanotime", "runtime.nanotime"}, 19},
+		{"go:noinline", "hot", []string{}, 22},
+	}
+	if len(ctx.Directives) != len(want) {
+		t.Fatalf("expected %d directives, got %+v", len(want), ctx.Directives)
+	}
+	for i, d := range ctx.Directives {
+		w := want[i]
+		if d.Name != w.name || d.Target != w.target || !slices.Equal(d.Args, w.args) || d.Position.Line != w.line {
+			t.Errorf("directive %d = %+v, want %+v", i, d, w)
+		}
+	}
+}
+
+func TestTransformPlusBuild(t *testing.T) {
+	src := `// +build linux darwin
+// +build !cgo
+
+package example
+
+// Code generated by hand. DO NOT EDIT.
+`
+	ctx := transformSource(t, src)
+
+	if bc := ctx.BuildConstraint; bc == nil || bc.Expression != "(linux || darwin) && !cgo" || bc.Position.Line != 1 {
+		t.Errorf("build constraint = %+v", bc)
+	}
+	if ctx.Generated != nil || ctx.Directives != nil {
+		t.Errorf("expected no generated marker or directives, got %+v, %+v", ctx.Generated, ctx.Directives)
+	}
+}
+
 func TestTransformCalls(t *testing.T) {
 	src := `package example
 
@@ -203,6 +317,472 @@ func helper() {}
 	}
 }
 
+func TestTransformCallsResolved(t *testing.T) {
+	src := `package example
+
+import (
+	stderrors "errors"
+	"io"
+	"net/http"
+)
+
+type Client struct{ http *http.Client }
+
+func (c *Client) fetch(r io.Reader, f func()) {
+	http := c.http
+	_, _ = http.Get("https://example.com")
+	_ = stderrors.New("x")
+	_, _ = r.Read(nil)
+	_ = len("x")
+	_ = []byte("x")
+	f()
+}
+`
+	ctx := transformTypedSource(t, src)
+
+	tests := []struct {
+		function     string
+		pkg          string
+		receiver     string
+		receiverType string
+		callee       string
+		kind         string
+	}{
+		{"Get", "net/http", "http", "*net/http.Client", "net/http.(*Client).Get", "method"},
+		{"New", "errors", "", "", "errors.New", "function"},
+		{"Read", "io", "r", "io.Reader", "io.Reader.Read", "interface_method"},
+		{"len", "", "", "", "len", "builtin"},
+		{"", "", "", "", "[]byte", "conversion"},
+		{"f", "", "", "", "", "dynamic"},
+	}
+
+	if len(ctx.Calls) != len(tests) {
+		t.Fatalf("expected %d calls, got %d: %+v", len(tests), len(ctx.Calls), ctx.Calls)
+	}
+	for i, tt := range tests {
+		call := ctx.Calls[i]
+		if call.Function != tt.function || call.Package != tt.pkg || call.Receiver != tt.receiver ||
+			call.ReceiverType != tt.receiverType || call.Callee != tt.callee || call.Kind != tt.kind {
+			t.Errorf("call %d: got %+v, want %+v", i, call, tt)
+		}
+	}
+}
+
+func TestTransformQualifiedNames(t *testing.T) {
+	src := `package example
+
+type Box[T any] struct{ v T }
+
+func (b *Box[T]) Get() T { return b.v }
+
+func (Box[T]) Put() {}
+
+func Run() {}
+`
+	ctx := transformTypedSource(t, src)
+
+	want := []string{"example.com/example.(*Box).Get", "example.com/example.Box.Put", "example.com/example.Run"}
+	if len(ctx.Functions) != len(want) {
+		t.Fatalf("expected %d functions, got %+v", len(want), ctx.Functions)
+	}
+	for i, fn := range ctx.Functions {
+		if fn.QualifiedName != want[i] {
+			t.Errorf("function %s: qualified name = %q, want %q", fn.Name, fn.QualifiedName, want[i])
+		}
+		if fn.Callees != nil || fn.Callers != nil {
+			t.Errorf("function %s: expected no callees or callers without a call graph, got %v and %v", fn.Name, fn.Callees, fn.Callers)
+		}
+	}
+
+	untyped := transformSource(t, src)
+	for _, fn := range untyped.Functions {
+		if fn.QualifiedName != "" {
+			t.Errorf("function %s: expected no qualified name without types, got %q", fn.Name, fn.QualifiedName)
+		}
+	}
+}
+
+func TestTransformTaintFlows(t *testing.T) {
+	step := func(file string, line int) model.TaintStep {
+		return model.TaintStep{Kind: "sink", Function: "os/exec.Command", Position: model.Position{File: file, Line: line}}
+	}
+	here := model.TaintFlow{Sink: step("test.go", 3)}
+	elsewhere := model.TaintFlow{Sink: step("other.go", 3)}
+
+	ctx := transformTypedSource(t, "package example\n", transformer.WithTaintFlows([]model.TaintFlow{here, elsewhere}))
+	if len(ctx.TaintFlows) != 1 || ctx.TaintFlows[0].Sink != here.Sink {
+		t.Errorf("expected only the flow with its sink in test.go, got %+v", ctx.TaintFlows)
+	}
+}
+
+func TestTransformTypeUsages(t *testing.T) {
+	src := `package example
+
+import (
+	"context"
+	iofs "io/fs"
+	"time"
+)
+
+type Handler struct {
+	context.Context
+	Timeout time.Duration
+}
+
+type Set[T comparable] map[T]struct{}
+
+func (h *Handler) Open(fsys iofs.FS, name string) (iofs.File, error) {
+	var d time.Duration
+	_ = time.Duration(d)
+	_ = Handler{}
+	if f, ok := fsys.(iofs.ReadFileFS); ok {
+		_ = f
+	}
+	return fsys.Open(name)
+}
+`
+	ctx := transformTypedSource(t, src)
+
+	tests := []struct {
+		typeName   string
+		pkg        string
+		inFunction string
+		context    string
+	}{
+		{"Context", "context", "", "embedding"},
+		{"Duration", "time", "", "field"},
+		{"Handler", "example.com/example", "Open", "receiver"},
+		{"FS", "io/fs", "Open", "parameter"},
+		{"File", "io/fs", "Open", "return"},
+		{"Duration", "time", "Open", "var"},
+		{"Duration", "time", "Open", "conversion"},
+		{"Handler", "example.com/example", "Open", "composite_literal"},
+		{"ReadFileFS", "io/fs", "Open", "type_assertion"},
+	}
+
+	if len(ctx.TypeUsages) != len(tests) {
+		t.Fatalf("expected %d type usages, got %d: %+v", len(tests), len(ctx.TypeUsages), ctx.TypeUsages)
+	}
+	for i, tt := range tests {
+		u := ctx.TypeUsages[i]
+		if u.TypeName != tt.typeName || u.Package != tt.pkg || u.InFunction != tt.inFunction || u.Context != tt.context {
+			t.Errorf("type usage %d: got %+v, want %+v", i, u, tt)
+		}
+	}
+}
+
+func TestTransformTypeUsagesWithoutTypes(t *testing.T) {
+	src := `package example
+
+import iofs "io/fs"
+
+func Open(fsys iofs.FS, name string) Result {
+	return Result{}
+}
+`
+	ctx := transformSource(t, src)
+
+	want := []model.TypeUsageInfo{
+		{TypeName: "FS", Package: "iofs", InFunction: "Open", Context: "parameter"},
+		{TypeName: "Result", InFunction: "Open", Context: "return"},
+		{TypeName: "Result", InFunction: "Open", Context: "composite_literal"},
+	}
+	if len(ctx.TypeUsages) != len(want) {
+		t.Fatalf("expected %d type usages, got %d: %+v", len(want), len(ctx.TypeUsages), ctx.TypeUsages)
+	}
+	for i, w := range want {
+		u := ctx.TypeUsages[i]
+		u.Position = model.Position{}
+		if u != w {
+			t.Errorf("type usage %d: got %+v, want %+v", i, u, w)
+		}
+	}
+}
+
+func TestTransformFieldAccesses(t *testing.T) {
+	src := `package example
+
+import "net/url"
+
+type Base struct{ ID int }
+
+type User struct {
+	Base
+	PasswordHash string
+	URL          *url.URL
+}
+
+func (u *User) Name() string { return "" }
+
+func reset(u *User) {
+	u.PasswordHash = ""
+	u.ID++
+	_ = u.URL.Host
+	_ = u.Name()
+	_ = User{PasswordHash: "x"}
+}
+`
+	ctx := transformTypedSource(t, src)
+
+	want := []model.FieldAccessInfo{
+		{Field: "PasswordHash", Receiver: "u", Type: "User", Package: "example.com/example", IsWrite: true, InFunction: "reset"},
+		{Field: "ID", Receiver: "u", Type: "Base", Package: "example.com/example", IsWrite: true, InFunction: "reset"},
+		{Field: "Host", Receiver: "u.URL", Type: "URL", Package: "net/url", InFunction: "reset"},
+		{Field: "URL", Receiver: "u", Type: "User", Package: "example.com/example", InFunction: "reset"},
+		{Field: "PasswordHash", Receiver: "User{...}", Type: "User", Package: "example.com/example", IsWrite: true, InFunction: "reset"},
+	}
+	if len(ctx.FieldAccess) != len(want) {
+		t.Fatalf("expected %d field accesses, got %d: %+v", len(want), len(ctx.FieldAccess), ctx.FieldAccess)
+	}
+	for i, w := range want {
+		got := ctx.FieldAccess[i]
+		got.Position = model.Position{}
+		if got != w {
+			t.Errorf("field access %d: got %+v, want %+v", i, got, w)
+		}
+	}
+}
+
+func TestTransformImplements(t *testing.T) {
+	src := `package example
+
+import "fmt"
+
+type Namer interface{ Name() string }
+
+type Buffer struct{}
+
+func (b *Buffer) Read(p []byte) (int, error) { return 0, nil }
+func (Buffer) String() string                { return "" }
+func (Buffer) Error() string                 { return "" }
+func (*Buffer) Name() string                 { return "" }
+
+type Set[T any] struct{}
+
+func (Set[T]) String() string { return fmt.Sprint() }
+`
+	ctx := transformTypedSource(t, src, transformer.WithInterfaces([]string{"error", "io.Reader", "sort.Interface"}))
+
+	if len(ctx.Types) != 3 {
+		t.Fatalf("expected 3 types, got %d", len(ctx.Types))
+	}
+
+	buffer := ctx.Types[1]
+	if want := []string{"error", "fmt.Stringer"}; !slices.Equal(buffer.Implements, want) {
+		t.Errorf("Buffer implements %v, want %v", buffer.Implements, want)
+	}
+	if want := []string{"Namer", "io.Reader"}; !slices.Equal(buffer.PointerImplements, want) {
+		t.Errorf("Buffer pointer implements %v, want %v", buffer.PointerImplements, want)
+	}
+
+	if set := ctx.Types[2]; set.Implements != nil || set.PointerImplements != nil {
+		t.Errorf("generic Set implements %v and %v, want nothing", set.Implements, set.PointerImplements)
+	}
+}
+
+func TestTransformUnresolvedInterfaces(t *testing.T) {
+	src := `package example
+
+import "fmt"
+
+type File struct{}
+
+func (File) Read(p []byte) (int, error) { return 0, fmt.Errorf("eof") }
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, 0)
+	if err != nil {
+		t.Fatalf("parsing source: %v", err)
+	}
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	pkg, err := (&types.Config{Importer: importer.Default()}).Check("example.com/example", fset, []*ast.File{file}, info)
+	if err != nil {
+		t.Fatalf("type-checking source: %v", err)
+	}
+
+	pass := &analysis.Pass{Fset: fset, Files: []*ast.File{file}, Pkg: pkg, TypesInfo: info}
+	trans := transformer.New(pass, "example.com/example", transformer.WithInterfaces([]string{"io.Reader", "example.com/missing.Thing"}))
+
+	// io is imported by fmt, so io.Reader resolves without a direct import.
+	ctx := trans.Transform(file, "test.go")
+	if want := []string{"io.Reader"}; !slices.Equal(ctx.Types[0].Implements, want) {
+		t.Errorf("File implements %v, want %v", ctx.Types[0].Implements, want)
+	}
+	if got, want := trans.UnresolvedInterfaces(), []string{"example.com/missing.Thing"}; !slices.Equal(got, want) {
+		t.Errorf("UnresolvedInterfaces() = %v, want %v", got, want)
+	}
+}
+
+func TestTransformMethodSets(t *testing.T) {
+	src := `package example
+
+import "sync"
+
+type Base struct{}
+
+func (Base) ID() string { return "" }
+
+type Service struct {
+	Base
+	sync.Mutex
+}
+
+func (s *Service) Start() error { return nil }
+func (s Service) name() string  { return "" }
+`
+	ctx := transformTypedSource(t, src)
+
+	service := ctx.Types[1]
+	if service.Name != "Service" {
+		t.Fatalf("expected Service, got %s", service.Name)
+	}
+
+	type method struct {
+		name     string
+		pointer  bool
+		promoted string
+	}
+	var declared []method
+	for _, m := range service.Methods {
+		declared = append(declared, method{m.Name, m.PointerReceiver, m.PromotedFrom})
+	}
+	if want := []method{{"Start", true, ""}, {"name", false, ""}}; !slices.Equal(declared, want) {
+		t.Errorf("declared methods = %v, want %v", declared, want)
+	}
+
+	var set []method
+	for _, m := range service.MethodSet {
+		set = append(set, method{m.Name, m.PointerReceiver, m.PromotedFrom})
+	}
+	want := []method{
+		{"ID", false, "Base"},
+		{"Lock", true, "sync.Mutex"},
+		{"Start", true, ""},
+		{"TryLock", true, "sync.Mutex"},
+		{"Unlock", true, "sync.Mutex"},
+		{"name", false, ""},
+	}
+	if !slices.Equal(set, want) {
+		t.Errorf("method set = %v, want %v", set, want)
+	}
+}
+
+func TestBuildPackageContextMergesMethods(t *testing.T) {
+	decls := transformSource(t, "package example\n\ntype Service struct{}\n\nfunc (s *Service) Start() {}\n")
+	methods := transformSource(t, "package example\n\nfunc (s Service) Stop() {}\n")
+
+	if got := len(decls.Types[0].Methods); got != 1 {
+		t.Fatalf("expected 1 method declared in the file, got %d", got)
+	}
+
+	pkg := transformer.BuildPackageContext([]*model.CodeContext{decls, methods})
+	pointer := make(map[string]bool)
+	for _, m := range pkg.AllTypes[0].Methods {
+		pointer[m.Name] = m.PointerReceiver
+	}
+	if want := map[string]bool{"Start": true, "Stop": false}; !maps.Equal(pointer, want) {
+		t.Errorf("package methods of Service = %v, want %v", pointer, want)
+	}
+}
+
+func TestBuildModuleContext(t *testing.T) {
+	api := &model.CodeContext{
+		FilePath: "/repo/internal/api/api.go",
+		Package:  model.PackageInfo{Name: "api", Path: "example.com/repo/internal/api", Position: model.Position{File: "api.go", Line: 1, Column: 1}},
+		Imports:  []model.ImportInfo{{Path: "fmt", Position: model.Position{File: "api.go", Line: 3}}},
+		Types:    []model.TypeInfo{{Name: "Server", Kind: "struct", IsExported: true, Position: model.Position{File: "api.go", Line: 5}}},
+		Functions: []model.FunctionInfo{
+			{Name: "Run", Receiver: "*Server", IsExported: true, Position: model.Position{File: "api.go", Line: 7}},
+		},
+	}
+	cmd := &model.CodeContext{
+		FilePath: "/repo/cmd/app/main.go",
+		Package:  model.PackageInfo{Name: "main", Path: "example.com/repo/cmd/app", Position: model.Position{File: "main.go", Line: 1, Column: 1}},
+		Imports: []model.ImportInfo{
+			{Path: "example.com/repo/internal/api", Position: model.Position{File: "main.go", Line: 4, Column: 2}},
+			{Path: "example.com/repo/internal/unloaded", Position: model.Position{File: "main.go", Line: 5, Column: 2}},
+			{Path: "example.com/other", Position: model.Position{File: "main.go", Line: 6, Column: 2}},
+		},
+	}
+
+	mod := transformer.BuildModuleContext("example.com/repo", [][]*model.CodeContext{{cmd}, {api}})
+
+	if len(mod.Packages) != 2 || mod.Packages[0].Path != "example.com/repo/cmd/app" {
+		t.Fatalf("packages = %+v, want cmd/app and internal/api sorted by path", mod.Packages)
+	}
+	summary := mod.Packages[1]
+	if summary.Position.File != "/repo/internal/api/api.go" || !slices.Equal(summary.Imports, []string{"fmt"}) {
+		t.Errorf("api summary = %+v", summary)
+	}
+	if len(summary.Types) != 1 || summary.Types[0].Position.File != "/repo/internal/api/api.go" {
+		t.Errorf("api types = %+v, want Server positioned by path", summary.Types)
+	}
+	if len(summary.Functions) != 1 || summary.Functions[0].Kind != "method" || summary.Functions[0].Receiver != "*Server" {
+		t.Errorf("api functions = %+v, want method Run", summary.Functions)
+	}
+
+	want := []model.ImportEdge{
+		{From: "example.com/repo/cmd/app", To: "example.com/repo/internal/api", Position: model.Position{File: "/repo/cmd/app/main.go", Line: 4, Column: 2}},
+		{From: "example.com/repo/cmd/app", To: "example.com/repo/internal/unloaded", Position: model.Position{File: "/repo/cmd/app/main.go", Line: 5, Column: 2}},
+	}
+	if !slices.Equal(mod.Imports, want) {
+		t.Errorf("imports = %+v, want %+v", mod.Imports, want)
+	}
+}
+
+func TestTransformCallScopes(t *testing.T) {
+	src := `package example
+
+var config = mustLoad()
+
+var handler = func() { serve() }
+
+func init() { register() }
+
+func run(ch chan int) {
+	defer cleanup()
+	for range 3 {
+		go func() {
+			work()
+			func() { nested() }()
+		}()
+	}
+	select {
+	case <-ch:
+		done()
+	}
+}
+`
+	ctx := transformSource(t, src)
+
+	type scope struct {
+		function, inFunction, parent, callee  string
+		init, inGo, inDefer, inLoop, inSelect bool
+	}
+	want := []scope{
+		{function: "mustLoad", init: true},
+		{function: "serve", inFunction: "init.func1"},
+		{function: "register", inFunction: "init", init: true},
+		{function: "cleanup", inFunction: "run", inDefer: true},
+		{function: "(anonymous)", inFunction: "run", callee: "run.func1", inGo: true, inLoop: true},
+		{function: "work", inFunction: "run.func1", parent: "run", inGo: true, inLoop: true},
+		{function: "(anonymous)", inFunction: "run.func1", parent: "run", callee: "run.func1.1", inGo: true, inLoop: true},
+		{function: "nested", inFunction: "run.func1.1", parent: "run.func1", inGo: true, inLoop: true},
+		{function: "done", inFunction: "run", inSelect: true},
+	}
+
+	if len(ctx.Calls) != len(want) {
+		t.Fatalf("expected %d calls, got %d: %+v", len(want), len(ctx.Calls), ctx.Calls)
+	}
+	for i, w := range want {
+		c := ctx.Calls[i]
+		got := scope{c.Function, c.InFunction, c.ParentFunction, c.Callee, c.InInit, c.InGo, c.InDefer, c.InLoop, c.InSelect}
+		if got != w {
+			t.Errorf("call %d: got %+v, want %+v", i, got, w)
+		}
+	}
+}
+
 func TestTransformConstants(t *testing.T) {
 	src := `package example
 
@@ -234,6 +814,281 @@ var globalVar = "value"
 	}
 }
 
+func TestTransformLocals(t *testing.T) {
+	src := `package example
+
+import "os"
+
+var global = 1
+
+func run(path string) {
+	type pair struct{ a, b int }
+	f, _ := os.Open(path)
+	_ = f.Close()
+	var n int = 2
+	const limit = 3
+	n += limit
+	for i := range n {
+		go func() { done := i > 0; _ = done }()
+	}
+}
+
+type count int
+`
+	ctx := transformTypedSource(t, src)
+
+	var typeNames []string
+	for _, typ := range ctx.Types {
+		typeNames = append(typeNames, typ.Name)
+	}
+	if want := []string{"pair", "count"}; !slices.Equal(typeNames, want) {
+		t.Errorf("types = %v, want %v", typeNames, want)
+	}
+
+	type local struct{ name, typ, inFunction string }
+	var vars []local
+	for _, v := range ctx.Variables {
+		vars = append(vars, local{v.Name, v.Type, v.InFunction})
+	}
+	wantVars := []local{
+		{"global", "int", ""},
+		{"f", "*os.File", "run"},
+		{"n", "int", "run"},
+		{"i", "int", "run"},
+		{"done", "bool", "run.func1"},
+	}
+	if !slices.Equal(vars, wantVars) {
+		t.Errorf("variables = %+v, want %+v", vars, wantVars)
+	}
+	if len(ctx.Constants) != 1 || ctx.Constants[0].Name != "limit" || ctx.Constants[0].InFunction != "run" {
+		t.Errorf("constants = %+v, want limit in run", ctx.Constants)
+	}
+
+	type assignment struct {
+		target, typ, value string
+		index              int
+		op                 string
+		discarded          bool
+		inFunction         string
+	}
+	var got []assignment
+	for _, a := range ctx.Assignments {
+		got = append(got, assignment{a.Target, a.Type, a.Value, a.ValueIndex, a.Op, a.IsDiscarded, a.InFunction})
+	}
+	want := []assignment{
+		{"f", "*os.File", "os.Open(...)", 0, ":=", false, "run"},
+		{"_", "error", "os.Open(...)", 1, ":=", true, "run"},
+		{"_", "error", "f.Close(...)", 0, "=", true, "run"},
+		{"n", "int", "2", 0, "var", false, "run"},
+		{"n", "int", "limit", 0, "+=", false, "run"},
+		{"i", "int", "range n", 0, ":=", false, "run"},
+		{"done", "bool", "i > 0", 0, ":=", false, "run.func1"},
+		{"_", "bool", "done", 0, "=", true, "run.func1"},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("assignments =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestTransformLocalsWithoutTypes(t *testing.T) {
+	src := `package example
+
+func run() {
+	var n int
+	x, err := load(n)
+	_ = err
+}
+`
+	ctx := transformSource(t, src)
+
+	if len(ctx.Variables) != 3 {
+		t.Fatalf("expected 3 variables, got %+v", ctx.Variables)
+	}
+	if v := ctx.Variables[0]; v.Name != "n" || v.Type != "int" || v.InFunction != "run" {
+		t.Errorf("variable 0 = %+v, want n int in run", v)
+	}
+	if v := ctx.Variables[1]; v.Name != "x" || v.Value != "load(...)" {
+		t.Errorf("variable 1 = %+v, want x = load(...)", v)
+	}
+
+	if len(ctx.Assignments) != 3 {
+		t.Fatalf("expected 3 assignments, got %+v", ctx.Assignments)
+	}
+	if a := ctx.Assignments[2]; !a.IsDiscarded || a.Value != "err" || a.Type != "" {
+		t.Errorf("assignment 2 = %+v, want untyped discard of err", a)
+	}
+}
+
+func TestTransformGenerics(t *testing.T) {
+	src := `package example
+
+import "cmp"
+
+type Set[T comparable] map[T]struct{}
+
+type Pair[K cmp.Ordered, V any] struct {
+	Key   K
+	Value V
+}
+
+func Max[T ~int | ~float64](a, b T) T { return max(a, b) }
+
+func Keys[K comparable, V any](m map[K]V) Set[K] { return nil }
+
+func run(pairs []Pair[string, int]) {
+	_ = Max(1.5, 2)
+	_ = Keys[string, int](nil)
+}
+`
+	ctx := transformTypedSource(t, src)
+
+	types := make(map[string][]model.TypeParamInfo)
+	for _, typ := range ctx.Types {
+		types[typ.Name] = typ.TypeParams
+	}
+	if want := []model.TypeParamInfo{{Name: "T", Constraint: "comparable"}}; !slices.Equal(types["Set"], want) {
+		t.Errorf("Set type params = %+v, want %+v", types["Set"], want)
+	}
+	if want := []model.TypeParamInfo{{Name: "K", Constraint: "cmp.Ordered"}, {Name: "V", Constraint: "any"}}; !slices.Equal(types["Pair"], want) {
+		t.Errorf("Pair type params = %+v, want %+v", types["Pair"], want)
+	}
+
+	functions := make(map[string]model.FunctionInfo)
+	for _, fn := range ctx.Functions {
+		functions[fn.Name] = fn
+	}
+	if want := []model.TypeParamInfo{{Name: "T", Constraint: "~int | ~float64"}}; !slices.Equal(functions["Max"].TypeParams, want) {
+		t.Errorf("Max type params = %+v, want %+v", functions["Max"].TypeParams, want)
+	}
+	if got := functions["Keys"].Returns[0].Type; got != "Set[K]" {
+		t.Errorf("Keys return type = %q, want Set[K]", got)
+	}
+	if got := functions["run"].Parameters[0].Type; got != "[]Pair[string, int]" {
+		t.Errorf("run parameter type = %q, want []Pair[string, int]", got)
+	}
+
+	typeArgs := make(map[string][]string)
+	for _, call := range ctx.Calls {
+		typeArgs[call.Function] = call.TypeArgs
+	}
+	if want := []string{"float64"}; !slices.Equal(typeArgs["Max"], want) {
+		t.Errorf("Max type args = %v, want %v", typeArgs["Max"], want)
+	}
+	if want := []string{"string", "int"}; !slices.Equal(typeArgs["Keys"], want) {
+		t.Errorf("Keys type args = %v, want %v", typeArgs["Keys"], want)
+	}
+	if typeArgs["max"] != nil {
+		t.Errorf("builtin max has type args %v", typeArgs["max"])
+	}
+}
+
+func TestTransformGenericsWithoutTypes(t *testing.T) {
+	src := `package example
+
+type List[T any] struct{ items []T }
+
+func (l *List[T]) Push(v T) {}
+
+func Map[S ~[]E, E any](s S, f func(E) E) S { return s }
+`
+	ctx := transformSource(t, src)
+
+	if want := []model.TypeParamInfo{{Name: "T", Constraint: "any"}}; !slices.Equal(ctx.Types[0].TypeParams, want) {
+		t.Errorf("List type params = %+v, want %+v", ctx.Types[0].TypeParams, want)
+	}
+	if got := ctx.Functions[0].Receiver; got != "*List[T]" {
+		t.Errorf("Push receiver = %q, want *List[T]", got)
+	}
+	want := []model.TypeParamInfo{{Name: "S", Constraint: "~[]E"}, {Name: "E", Constraint: "any"}}
+	if !slices.Equal(ctx.Functions[1].TypeParams, want) {
+		t.Errorf("Map type params = %+v, want %+v", ctx.Functions[1].TypeParams, want)
+	}
+}
+
+func TestTransformTypeStrings(t *testing.T) {
+	src := `package example
+
+import (
+	stdctx "context"
+	"net/http"
+)
+
+type Handler struct {
+	Routes map[string]func(w http.ResponseWriter, r *http.Request) error
+	Window [4]byte
+	Meta   struct{ ID int }
+}
+
+func Serve(c stdctx.Context, reqs []**http.Request, opts ...string) (any, error) { return nil, nil }
+`
+	ctx := transformTypedSource(t, src)
+
+	fields := ctx.Types[0].Fields
+	wantFields := []string{
+		"map[string]func(w http.ResponseWriter, r *http.Request) error",
+		"[4]byte",
+		"struct{ID int}",
+	}
+	for i, want := range wantFields {
+		if fields[i].Type != want {
+			t.Errorf("field %s type = %q, want %q", fields[i].Name, fields[i].Type, want)
+		}
+	}
+
+	params := ctx.Functions[0].Parameters
+	if params[0].Type != "context.Context" {
+		t.Errorf("aliased context parameter type = %q, want context.Context", params[0].Type)
+	}
+	if params[2].Type != "...string" {
+		t.Errorf("variadic parameter type = %q, want ...string", params[2].Type)
+	}
+
+	ctxInfo := params[0].TypeInfo
+	if ctxInfo == nil || ctxInfo.Kind != "named" || ctxInfo.Package != "context" || ctxInfo.Name != "Context" || ctxInfo.Underlying != "interface" {
+		t.Errorf("context parameter type_info = %+v", ctxInfo)
+	}
+	reqs := params[1].TypeInfo
+	if reqs == nil || reqs.Kind != "slice" || reqs.Elem == nil {
+		t.Fatalf("reqs type_info = %+v, want slice with elem", reqs)
+	}
+	if elem := reqs.Elem; elem.Kind != "pointer" || elem.PointerDepth != 2 || elem.Package != "net/http" || elem.Name != "Request" || elem.Underlying != "struct" {
+		t.Errorf("reqs elem type_info = %+v, want **net/http.Request", elem)
+	}
+	if routes := fields[0].TypeInfo; routes == nil || routes.Key.Name != "string" || routes.Elem.Kind != "func" {
+		t.Errorf("Routes type_info = %+v, want map from string to func", routes)
+	}
+}
+
+func TestFormatTypeWithoutTypes(t *testing.T) {
+	src := `package example
+
+type Handler struct {
+	Routes map[string]func(w ResponseWriter, r *Request) error
+	Window [size]byte
+	Meta   struct {
+		ID int ` + "`json:\"id\"`" + `
+	}
+	Source interface {
+		Read(p []byte) (n int, err error)
+		Closer
+	}
+}
+`
+	ctx := transformSource(t, src)
+
+	want := []string{
+		"map[string]func(w ResponseWriter, r *Request) error",
+		"[size]byte",
+		"struct{ID int `json:\"id\"`}",
+		"interface{Read(p []byte) (n int, err error); Closer}",
+	}
+	for i, w := range want {
+		if got := ctx.Types[0].Fields[i]; got.Type != w || got.TypeInfo != nil {
+			t.Errorf("field %s type = %q (type_info %+v), want %q", got.Name, got.Type, got.TypeInfo, w)
+		}
+	}
+}
+
 func TestComplexity(t *testing.T) {
 	src := `package example
 
@@ -274,6 +1129,41 @@ func complex(x int) {
 	}
 }
 
+// transformTypedSource transforms src with full type information, as the
+// golangci-lint plugin does.
+func transformTypedSource(t *testing.T, src string, opts ...transformer.Option) *model.CodeContext {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "test.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parsing source: %v", err)
+	}
+
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Instances:  make(map[*ast.Ident]types.Instance),
+	}
+	conf := &types.Config{Importer: importer.Default()}
+	pkg, err := conf.Check("example.com/example", fset, []*ast.File{file}, info)
+	if err != nil {
+		t.Fatalf("type-checking source: %v", err)
+	}
+
+	pass := &analysis.Pass{
+		Fset:      fset,
+		Files:     []*ast.File{file},
+		Pkg:       pkg,
+		TypesInfo: info,
+	}
+
+	trans := transformer.New(pass, "example.com/example", opts...)
+	return trans.Transform(file, "test.go")
+}
+
 func transformSource(t *testing.T, src string) *model.CodeContext {
 	t.Helper()
 
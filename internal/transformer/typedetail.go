@@ -0,0 +1,85 @@
+package transformer
+
+import (
+	"go/types"
+
+	"github.com/burdzwastaken/regolint/internal/model"
+)
+
+// typeDetail describes typ for policies, or returns nil when typ is nil.
+func typeDetail(typ types.Type) *model.TypeDetail {
+	if typ == nil {
+		return nil
+	}
+
+	detail := &model.TypeDetail{Kind: typeKind(typ)}
+
+	base := types.Unalias(typ)
+	for {
+		p, ok := base.(*types.Pointer)
+		if !ok {
+			break
+		}
+		detail.PointerDepth++
+		base = types.Unalias(p.Elem())
+	}
+
+	switch b := base.(type) {
+	case *types.Named:
+		detail.Name = b.Obj().Name()
+		if b.Obj().Pkg() != nil {
+			detail.Package = b.Obj().Pkg().Path()
+		}
+	case *types.Basic:
+		detail.Name = b.Name()
+	case *types.TypeParam:
+		detail.Name = b.Obj().Name()
+	}
+	detail.Underlying = typeKind(base.Underlying())
+
+	switch u := types.Unalias(typ).(type) {
+	case *types.Pointer:
+		detail.Elem = typeDetail(u.Elem())
+	case *types.Slice:
+		detail.Elem = typeDetail(u.Elem())
+	case *types.Array:
+		detail.Elem = typeDetail(u.Elem())
+	case *types.Chan:
+		detail.Elem = typeDetail(u.Elem())
+	case *types.Map:
+		detail.Key = typeDetail(u.Key())
+		detail.Elem = typeDetail(u.Elem())
+	}
+
+	return detail
+}
+
+// typeKind names the kind of typ without looking through named types.
+func typeKind(typ types.Type) string {
+	switch types.Unalias(typ).(type) {
+	case *types.Basic:
+		return "basic"
+	case *types.Named:
+		return "named"
+	case *types.Pointer:
+		return "pointer"
+	case *types.Slice:
+		return "slice"
+	case *types.Array:
+		return "array"
+	case *types.Map:
+		return "map"
+	case *types.Chan:
+		return "chan"
+	case *types.Signature:
+		return "func"
+	case *types.Struct:
+		return "struct"
+	case *types.Interface:
+		return "interface"
+	case *types.TypeParam:
+		return "type_param"
+	default:
+		return "unknown"
+	}
+}
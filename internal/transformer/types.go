@@ -2,8 +2,10 @@ package transformer
 
 import (
 	"go/ast"
+	"strconv"
 
 	"github.com/burdzwastaken/regolint/internal/model"
+	"github.com/burdzwastaken/regolint/internal/structtag"
 )
 
 func (t *Transformer) extractType(spec *ast.TypeSpec, doc *ast.CommentGroup) model.TypeInfo {
@@ -15,6 +17,7 @@ func (t *Transformer) extractType(spec *ast.TypeSpec, doc *ast.CommentGroup) mod
 		Fields:     make([]model.FieldInfo, 0),
 		Methods:    make([]model.MethodInfo, 0),
 		Embeds:     make([]string, 0),
+		TypeParams: t.extractTypeParams(spec.TypeParams),
 	}
 
 	switch typeExpr := spec.Type.(type) {
@@ -29,6 +32,13 @@ func (t *Transformer) extractType(spec *ast.TypeSpec, doc *ast.CommentGroup) mod
 	default:
 		info.Kind = "alias"
 	}
+	info.Implements, info.PointerImplements = t.extractImplements(spec)
+
+	declared, methodSet := t.extractMethods(spec)
+	if info.Kind != "interface" && declared != nil {
+		info.Methods = declared
+	}
+	info.MethodSet = methodSet
 
 	return info
 }
@@ -42,16 +52,23 @@ func (t *Transformer) extractStructFields(st *ast.StructType) ([]model.FieldInfo
 	embeds := make([]string, 0)
 
 	for _, field := range st.Fields.List {
-		typeStr := t.formatType(field.Type)
+		typeStr := t.typeString(field.Type)
+		detail := typeDetail(t.typeOf(field.Type))
+
+		tags := extractTags(field.Tag)
+		tagMap, tagErrors := parseTags(tags)
 
 		if len(field.Names) == 0 {
 			embeds = append(embeds, typeStr)
 			fields = append(fields, model.FieldInfo{
 				Name:       typeStr,
 				Type:       typeStr,
+				TypeInfo:   detail,
 				IsEmbedded: true,
 				Position:   t.position(field.Pos()),
-				Tags:       extractTags(field.Tag),
+				Tags:       tags,
+				TagMap:     tagMap,
+				TagErrors:  tagErrors,
 			})
 			continue
 		}
@@ -60,9 +77,12 @@ func (t *Transformer) extractStructFields(st *ast.StructType) ([]model.FieldInfo
 			fields = append(fields, model.FieldInfo{
 				Name:       name.Name,
 				Type:       typeStr,
+				TypeInfo:   detail,
 				IsExported: isExported(name.Name),
 				Position:   t.position(name.Pos()),
-				Tags:       extractTags(field.Tag),
+				Tags:       tags,
+				TagMap:     tagMap,
+				TagErrors:  tagErrors,
 			})
 		}
 	}
@@ -80,7 +100,7 @@ func (t *Transformer) extractInterfaceMethods(iface *ast.InterfaceType) ([]model
 
 	for _, field := range iface.Methods.List {
 		if len(field.Names) == 0 {
-			embeds = append(embeds, t.formatType(field.Type))
+			embeds = append(embeds, t.typeString(field.Type))
 			continue
 		}
 
@@ -91,6 +111,7 @@ func (t *Transformer) extractInterfaceMethods(iface *ast.InterfaceType) ([]model
 					IsExported: isExported(name.Name),
 					Parameters: make([]model.ParameterInfo, 0),
 					Returns:    make([]model.ParameterInfo, 0),
+					Position:   t.position(name.Pos()),
 				}
 
 				if fn.Params != nil {
@@ -108,13 +129,22 @@ func (t *Transformer) extractInterfaceMethods(iface *ast.InterfaceType) ([]model
 	return methods, embeds
 }
 
+// extractTags returns the contents of a tag literal, raw or interpreted.
 func extractTags(tag *ast.BasicLit) string {
 	if tag == nil {
 		return ""
 	}
-	s := tag.Value
-	if len(s) >= 2 && s[0] == '`' && s[len(s)-1] == '`' {
-		return s[1 : len(s)-1]
+	s, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return tag.Value
 	}
 	return s
 }
+
+// parseTags parses the tags of a field that has any.
+func parseTags(tags string) (map[string]model.TagValue, []string) {
+	if tags == "" {
+		return nil, nil
+	}
+	return structtag.Parse(tags)
+}
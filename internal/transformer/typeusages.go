@@ -0,0 +1,192 @@
+package transformer
+
+import (
+	"cmp"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+
+	"github.com/burdzwastaken/regolint/internal/model"
+)
+
+// typeUsageCollector records references to named types along with how they
+// are used. Each identifier is recorded once, in the outermost context that
+// mentions it.
+type typeUsageCollector struct {
+	t      *Transformer
+	info   *types.Info
+	fn     string
+	seen   map[*ast.Ident]bool
+	usages []model.TypeUsageInfo
+}
+
+// extractTypeUsages records references to named types in file, sorted by
+// position.
+func (t *Transformer) extractTypeUsages(file *ast.File) []model.TypeUsageInfo {
+	c := &typeUsageCollector{
+		t:      t,
+		info:   t.pkg.TypesInfo,
+		seen:   make(map[*ast.Ident]bool),
+		usages: make([]model.TypeUsageInfo, 0),
+	}
+
+	for _, decl := range file.Decls {
+		c.fn = ""
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			c.fn = fn.Name.Name
+			if fn.Recv != nil {
+				c.fieldList(fn.Recv, "receiver")
+			}
+		}
+		ast.Inspect(decl, func(n ast.Node) bool {
+			c.declaration(n)
+			c.expression(n)
+			return true
+		})
+	}
+
+	slices.SortStableFunc(c.usages, func(a, b model.TypeUsageInfo) int {
+		return cmp.Or(cmp.Compare(a.Position.Line, b.Position.Line), cmp.Compare(a.Position.Column, b.Position.Column))
+	})
+	return c.usages
+}
+
+// declaration records the types named in signatures and declarations.
+func (c *typeUsageCollector) declaration(n ast.Node) {
+	switch n := n.(type) {
+	case *ast.FuncType:
+		c.fieldList(n.TypeParams, "type_constraint")
+		c.fieldList(n.Params, "parameter")
+		c.fieldList(n.Results, "return")
+	case *ast.StructType:
+		for _, field := range n.Fields.List {
+			c.refs(field.Type, cmp.Or(embedding(field), "field"))
+		}
+	case *ast.InterfaceType:
+		for _, field := range n.Methods.List {
+			c.refs(field.Type, embedding(field))
+		}
+	case *ast.TypeSpec:
+		c.fieldList(n.TypeParams, "type_constraint")
+		switch n.Type.(type) {
+		case *ast.StructType, *ast.InterfaceType, *ast.FuncType:
+		default:
+			c.refs(n.Type, "type_definition")
+		}
+	case *ast.ValueSpec:
+		c.refs(n.Type, "var")
+	}
+}
+
+// expression records the types named in expressions. With type information,
+// any other reference to a type is recorded with context "other".
+func (c *typeUsageCollector) expression(n ast.Node) {
+	switch n := n.(type) {
+	case *ast.CompositeLit:
+		c.refs(n.Type, "composite_literal")
+	case *ast.TypeAssertExpr:
+		c.refs(n.Type, "type_assertion")
+	case *ast.TypeSwitchStmt:
+		for _, stmt := range n.Body.List {
+			for _, expr := range stmt.(*ast.CaseClause).List {
+				c.refs(expr, "type_assertion")
+			}
+		}
+	case *ast.CallExpr:
+		if c.isConversion(n) {
+			c.refs(n.Fun, "conversion")
+		}
+	case *ast.SelectorExpr, *ast.Ident:
+		if c.info != nil {
+			c.refs(n.(ast.Expr), "other")
+		}
+	}
+}
+
+// embedding returns "embedding" for an embedded field, or "" otherwise.
+func embedding(field *ast.Field) string {
+	if len(field.Names) == 0 {
+		return "embedding"
+	}
+	return ""
+}
+
+func (c *typeUsageCollector) fieldList(fields *ast.FieldList, context string) {
+	if fields == nil {
+		return
+	}
+	for _, field := range fields.List {
+		c.refs(field.Type, context)
+	}
+}
+
+func (c *typeUsageCollector) isConversion(call *ast.CallExpr) bool {
+	fun := ast.Unparen(call.Fun)
+	if c.info != nil {
+		tv, ok := c.info.Types[fun]
+		return ok && tv.IsType()
+	}
+
+	switch fun.(type) {
+	case *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType:
+		return true
+	case *ast.StarExpr:
+		_, paren := call.Fun.(*ast.ParenExpr)
+		return paren
+	default:
+		return false
+	}
+}
+
+// refs records every named type mentioned in expr.
+func (c *typeUsageCollector) refs(expr ast.Expr, context string) {
+	if expr == nil {
+		return
+	}
+
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			if x, ok := n.X.(*ast.Ident); ok {
+				c.record(n.Sel, x.Name, context, n.Pos())
+				return false
+			}
+		case *ast.Ident:
+			c.record(n, "", context, n.Pos())
+		case *ast.FuncLit:
+			return false
+		}
+		return true
+	})
+}
+
+// record adds a usage of the type ident refers to. Without type information,
+// pkg is the qualifier as written and only type positions are recorded.
+func (c *typeUsageCollector) record(ident *ast.Ident, pkg, context string, pos token.Pos) {
+	if c.seen[ident] {
+		return
+	}
+
+	if c.info != nil {
+		obj, ok := c.info.Uses[ident].(*types.TypeName)
+		if !ok || obj.Pkg() == nil {
+			return
+		}
+		if _, isParam := obj.Type().(*types.TypeParam); isParam {
+			return
+		}
+		pkg = obj.Pkg().Path()
+	} else if context == "other" || pkg == "" && types.Universe.Lookup(ident.Name) != nil {
+		return
+	}
+
+	c.seen[ident] = true
+	c.usages = append(c.usages, model.TypeUsageInfo{
+		TypeName:   ident.Name,
+		Package:    pkg,
+		InFunction: c.fn,
+		Context:    context,
+		Position:   c.t.position(pos),
+	})
+}
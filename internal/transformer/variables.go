@@ -3,6 +3,7 @@ package transformer
 import (
 	"go/ast"
 	"go/token"
+	"go/types"
 
 	"github.com/burdzwastaken/regolint/internal/model"
 )
@@ -36,19 +37,19 @@ func (t *Transformer) extractGenDecl(decl *ast.GenDecl, ctx *model.CodeContext)
 func (t *Transformer) extractVariables(spec *ast.ValueSpec, isConst bool) []model.VariableInfo {
 	vars := make([]model.VariableInfo, 0, len(spec.Names))
 
-	var typeStr string
-	if spec.Type != nil {
-		typeStr = t.formatType(spec.Type)
-	}
-
 	for i, name := range spec.Names {
 		v := model.VariableInfo{
 			Name:       name.Name,
-			Type:       typeStr,
+			Type:       t.declaredType(spec, name),
 			IsExported: isExported(name.Name),
 			IsConst:    isConst,
 			Position:   t.position(name.Pos()),
 		}
+		if t.pkg.TypesInfo != nil {
+			if obj := t.pkg.TypesInfo.Defs[name]; obj != nil {
+				v.TypeInfo = typeDetail(obj.Type())
+			}
+		}
 
 		if i < len(spec.Values) {
 			v.Value = t.formatExpr(spec.Values[i])
@@ -59,3 +60,18 @@ func (t *Transformer) extractVariables(spec *ast.ValueSpec, isConst bool) []mode
 
 	return vars
 }
+
+// declaredType returns the type of the variable or constant name declared by
+// spec. With type information, the inferred type of an untyped declaration
+// is used; otherwise it is the type written, if any.
+func (t *Transformer) declaredType(spec *ast.ValueSpec, name *ast.Ident) string {
+	if t.pkg.TypesInfo != nil {
+		if obj := t.pkg.TypesInfo.Defs[name]; obj != nil {
+			return types.TypeString(obj.Type(), t.qualifier)
+		}
+	}
+	if spec.Type == nil {
+		return ""
+	}
+	return t.formatType(spec.Type)
+}
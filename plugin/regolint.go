@@ -6,10 +6,12 @@ import (
 	"go/ast"
 	"go/token"
 	"log"
+	"path/filepath"
 	"sync"
 
 	"github.com/burdzwastaken/regolint/internal/config"
 	"github.com/burdzwastaken/regolint/internal/evaluator"
+	"github.com/burdzwastaken/regolint/internal/fix"
 	"github.com/burdzwastaken/regolint/internal/model"
 	"github.com/burdzwastaken/regolint/internal/nolint"
 	"github.com/burdzwastaken/regolint/internal/transformer"
@@ -28,6 +30,7 @@ func init() {
 
 // Settings mirrors config options for golangci-lint integration.
 type Settings struct {
+	Config      string   `json:"config"`
 	PolicyDir   string   `json:"policy-dir"`
 	PolicyFiles []string `json:"policy-files"`
 	Disabled    []string `json:"disabled"`
@@ -50,80 +53,100 @@ func New(settings any) (register.LinterPlugin, error) {
 
 // BuildAnalyzers returns the regolint analyzer.
 func (p *RegolintPlugin) BuildAnalyzers() ([]*analysis.Analyzer, error) {
-	var (
-		evalOnce sync.Once
-		eval     *evaluator.Evaluator
-		evalErr  error
-		cfg      *config.Config
-	)
-
+	r := &runner{plugin: p}
 	analyzer := &analysis.Analyzer{
 		Name: name,
 		Doc:  doc,
-		Run: func(pass *analysis.Pass) (any, error) {
-			evalOnce.Do(func() {
-				cfg = p.buildConfig()
+		Run:  r.run,
+	}
 
-				policies, err := cfg.LoadPolicies()
-				if err != nil {
-					evalErr = err
-					return
-				}
+	return []*analysis.Analyzer{analyzer}, nil
+}
 
-				if len(policies) == 0 {
-					log.Printf("[regolint] warning: no policies found in %s", cfg.Policies.Directory)
-					return
-				}
+// runner holds the configuration and evaluator shared by the packages an
+// analyzer runs on, built lazily on the first package.
+type runner struct {
+	plugin *RegolintPlugin
 
-				eval, evalErr = evaluator.New(policies)
-			})
+	once sync.Once
+	cfg  *config.Config
+	eval *evaluator.Evaluator
+	err  error
+}
 
-			if evalErr != nil {
-				return nil, evalErr
-			}
+func (r *runner) init() {
+	r.cfg, r.err = r.plugin.buildConfig()
+	if r.err != nil {
+		return
+	}
 
-			if eval == nil {
-				return nil, nil
-			}
+	policies, err := r.cfg.LoadPolicies()
+	if err != nil {
+		r.err = err
+		return
+	}
 
-			// Use pass.Pkg.Path() directly - the standard approach for linters
-			modulePath := pass.Pkg.Path()
-			trans := transformer.New(pass, modulePath)
+	if len(policies) == 0 {
+		log.Printf("[regolint] warning: no policies found in %s", r.cfg.Policies.Directory)
+		return
+	}
 
-			for _, file := range pass.Files {
-				filePath := pass.Fset.Position(file.Pos()).Filename
+	timeout, err := r.cfg.GetTimeout()
+	if err != nil {
+		r.err = err
+		return
+	}
 
-				if cfg.ShouldSkip(filePath) {
-					continue
-				}
+	r.eval, r.err = evaluator.New(policies, evaluator.WithTimeout(timeout))
+}
 
-				codeCtx := trans.Transform(file, filePath)
+func (r *runner) run(pass *analysis.Pass) (any, error) {
+	r.once.Do(r.init)
 
-				violations, err := eval.Evaluate(context.Background(), codeCtx)
-				if err != nil {
-					return nil, fmt.Errorf("evaluating %s: %w", filePath, err)
-				}
+	if r.err != nil {
+		return nil, r.err
+	}
 
-				var filtered []model.Violation
-				for _, v := range violations {
-					if !cfg.IsRuleDisabled(v.Rule) {
-						filtered = append(filtered, v)
-					}
-				}
+	if r.eval == nil {
+		return nil, nil
+	}
 
-				filtered = nolint.FilterModelViolations(filtered, codeCtx.Nolints)
+	cfg, eval := r.cfg, r.eval
 
-				for _, v := range filtered {
-					pos := findPosition(pass, file, v.Position.Line)
-					pass.Reportf(pos, "[%s] %s", v.Rule, v.Message)
-				}
-			}
+	// Use pass.Pkg.Path() directly - the standard approach for linters
+	modulePath := pass.Pkg.Path()
+	trans := transformer.New(pass, modulePath, transformer.WithInterfaces(cfg.Analysis.Interfaces))
 
-			return nil, nil
-		},
+	fixPkg := &fix.Package{
+		Fset:      pass.Fset,
+		Files:     pass.Files,
+		Types:     pass.Pkg,
+		TypesInfo: pass.TypesInfo,
+		ReadFile:  pass.ReadFile,
 	}
 
-	return []*analysis.Analyzer{analyzer}, nil
+	var analyzed []analyzedFile
+
+	for _, file := range pass.Files {
+		filePath := pass.Fset.Position(file.Pos()).Filename
+
+		if cfg.ShouldSkip(filePath) || cfg.ExcludeGenerated && ast.IsGenerated(file) {
+			continue
+		}
+
+		codeCtx := trans.Transform(file, filePath)
+		analyzed = append(analyzed, analyzedFile{file: file, codeCtx: codeCtx})
+
+		violations, err := eval.Evaluate(context.Background(), codeCtx)
+		if err != nil {
+			return nil, fmt.Errorf("evaluating %s: %w", filePath, err)
+		}
+		fixPkg.Lower(violations, filePath)
+
+		report(pass, cfg, file, codeCtx, violations)
+	}
+
+	return nil, reportPackage(pass, cfg, eval, fixPkg, analyzed)
 }
 
 // GetLoadMode returns the load mode for the plugin.
@@ -131,27 +154,132 @@ func (p *RegolintPlugin) GetLoadMode() string {
 	return register.LoadModeTypesInfo
 }
 
-func (p *RegolintPlugin) buildConfig() *config.Config {
-	cfg := config.Default()
-	cfg.Exclude = nil
+// buildConfig resolves the config file the same way as the standalone CLI,
+// with plugin settings taking precedence.
+func (p *RegolintPlugin) buildConfig() (*config.Config, error) {
+	return config.Resolve(p.settings.Config, config.Overrides{
+		PolicyDir:   p.settings.PolicyDir,
+		PolicyFiles: p.settings.PolicyFiles,
+		Disabled:    p.settings.Disabled,
+		Exclude:     p.settings.Exclude,
+	})
+}
+
+type analyzedFile struct {
+	file    *ast.File
+	codeCtx *model.CodeContext
+}
+
+// reportPackage evaluates package-scoped rules and reports each violation
+// against the file it is anchored to.
+func reportPackage(pass *analysis.Pass, cfg *config.Config, eval *evaluator.Evaluator, fixPkg *fix.Package, analyzed []analyzedFile) error {
+	codeCtxs := make([]*model.CodeContext, 0, len(analyzed))
+	byFile := make(map[string]analyzedFile, len(analyzed))
+	for _, a := range analyzed {
+		codeCtxs = append(codeCtxs, a.codeCtx)
+		byFile[filepath.Base(a.codeCtx.FilePath)] = a
+	}
 
-	if p.settings.PolicyDir != "" {
-		cfg.Policies.Directory = p.settings.PolicyDir
+	pkgCtx := transformer.BuildPackageContext(codeCtxs)
+	if pkgCtx == nil {
+		return nil
+	}
+
+	violations, err := eval.EvaluatePackage(context.Background(), pkgCtx)
+	if err != nil {
+		return fmt.Errorf("evaluating package %s: %w", pass.Pkg.Path(), err)
+	}
+	fixPkg.Lower(violations, "")
+
+	grouped := make(map[string][]model.Violation)
+	var unanchored []model.Violation
+	for _, v := range violations {
+		name := filepath.Base(v.Position.File)
+		if _, ok := byFile[name]; ok {
+			grouped[name] = append(grouped[name], v)
+		} else {
+			unanchored = append(unanchored, v)
+		}
 	}
 
-	if len(p.settings.PolicyFiles) > 0 {
-		cfg.Policies.Files = p.settings.PolicyFiles
+	for _, a := range analyzed {
+		report(pass, cfg, a.file, a.codeCtx, grouped[filepath.Base(a.codeCtx.FilePath)])
 	}
+	reportUnanchored(pass, cfg, unanchored)
+
+	return nil
+}
 
-	if len(p.settings.Disabled) > 0 {
-		cfg.Rules.Disabled = p.settings.Disabled
+// reportUnanchored reports violations whose position names no analyzed file
+// at the package clause of the first file, as the CLI keeps them without a
+// file. They cannot be suppressed by nolint directives.
+func reportUnanchored(pass *analysis.Pass, cfg *config.Config, violations []model.Violation) {
+	if len(pass.Files) == 0 {
+		return
 	}
+	for _, v := range violations {
+		if cfg.IsRuleDisabled(v.Rule) {
+			continue
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:     pass.Files[0].Package,
+			Message: fmt.Sprintf("[%s] %s", v.Rule, v.Message),
+		})
+	}
+}
 
-	if len(p.settings.Exclude) > 0 {
-		cfg.Exclude = p.settings.Exclude
+func report(pass *analysis.Pass, cfg *config.Config, file *ast.File, codeCtx *model.CodeContext, violations []model.Violation) {
+	var filtered []model.Violation
+	for _, v := range violations {
+		if !cfg.IsRuleDisabled(v.Rule) {
+			v.Severity = cfg.GetSeverity(v.Rule, v.Severity)
+			filtered = append(filtered, v)
+		}
 	}
 
-	return cfg
+	filtered = nolint.FilterModelViolations(filtered, codeCtx.Nolints)
+
+	for _, v := range filtered {
+		pass.Report(analysis.Diagnostic{
+			Pos:            findPosition(pass, file, v.Position.Line),
+			Message:        fmt.Sprintf("[%s] %s", v.Rule, v.Message),
+			SuggestedFixes: suggestedFixes(pass, v.Fix),
+		})
+	}
+}
+
+// suggestedFixes converts the resolved edits of a fix to text edits that
+// golangci-lint and editors can apply.
+func suggestedFixes(pass *analysis.Pass, f *model.Fix) []analysis.SuggestedFix {
+	if f == nil || len(f.Edits) == 0 {
+		return nil
+	}
+
+	edits := make([]analysis.TextEdit, 0, len(f.Edits))
+	for _, e := range f.Edits {
+		tf := tokenFile(pass, e.Position.File)
+		if tf == nil || e.Offset == nil || e.EndOffset == nil || *e.EndOffset > tf.Size() {
+			return nil
+		}
+		edits = append(edits, analysis.TextEdit{
+			Pos:     tf.Pos(*e.Offset),
+			End:     tf.Pos(*e.EndOffset),
+			NewText: []byte(e.NewText),
+		})
+	}
+
+	return []analysis.SuggestedFix{{Message: f.Description, TextEdits: edits}}
+}
+
+// tokenFile finds the file of the package with the given base name.
+func tokenFile(pass *analysis.Pass, name string) *token.File {
+	for _, f := range pass.Files {
+		tf := pass.Fset.File(f.Pos())
+		if tf != nil && filepath.Base(tf.Name()) == name {
+			return tf
+		}
+	}
+	return nil
 }
 
 func findPosition(pass *analysis.Pass, file *ast.File, line int) token.Pos {
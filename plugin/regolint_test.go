@@ -1,9 +1,19 @@
 package plugin
 
 import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"slices"
 	"testing"
 
+	"github.com/burdzwastaken/regolint/internal/config"
+	"github.com/burdzwastaken/regolint/internal/evaluator"
+	"github.com/burdzwastaken/regolint/internal/fix"
+	"github.com/burdzwastaken/regolint/internal/model"
 	"github.com/golangci/plugin-module-register/register"
+	"golang.org/x/tools/go/analysis"
 )
 
 func TestNew(t *testing.T) {
@@ -105,9 +115,15 @@ func TestRegolintPlugin_buildConfig(t *testing.T) {
 			name:     "default config",
 			settings: Settings{},
 			check: func(t *testing.T, p *RegolintPlugin) {
-				cfg := p.buildConfig()
-				if cfg.Policies.Directory != ".regolint/policies" {
-					t.Errorf("default policy dir = %q, want %q", cfg.Policies.Directory, ".regolint/policies")
+				cfg, err := p.buildConfig()
+				if err != nil {
+					t.Fatalf("buildConfig() error = %v", err)
+				}
+				if cfg.Policies.Directory != config.DefaultPolicyDir {
+					t.Errorf("default policy dir = %q, want %q", cfg.Policies.Directory, config.DefaultPolicyDir)
+				}
+				if len(cfg.Exclude) != 0 {
+					t.Errorf("default exclude = %v, want none", cfg.Exclude)
 				}
 			},
 		},
@@ -117,7 +133,10 @@ func TestRegolintPlugin_buildConfig(t *testing.T) {
 				PolicyDir: "/custom/policies",
 			},
 			check: func(t *testing.T, p *RegolintPlugin) {
-				cfg := p.buildConfig()
+				cfg, err := p.buildConfig()
+				if err != nil {
+					t.Fatalf("buildConfig() error = %v", err)
+				}
 				if cfg.Policies.Directory != "/custom/policies" {
 					t.Errorf("policy dir = %q, want %q", cfg.Policies.Directory, "/custom/policies")
 				}
@@ -129,7 +148,10 @@ func TestRegolintPlugin_buildConfig(t *testing.T) {
 				Disabled: []string{"RULE001", "RULE002"},
 			},
 			check: func(t *testing.T, p *RegolintPlugin) {
-				cfg := p.buildConfig()
+				cfg, err := p.buildConfig()
+				if err != nil {
+					t.Fatalf("buildConfig() error = %v", err)
+				}
 				if len(cfg.Rules.Disabled) != 2 {
 					t.Errorf("disabled rules count = %d, want 2", len(cfg.Rules.Disabled))
 				}
@@ -144,3 +166,59 @@ func TestRegolintPlugin_buildConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestReportPackage(t *testing.T) {
+	eval, err := evaluator.New(map[string]string{"pkg.rego": `package regolint.rules.test.pkg
+
+deny_package contains violation if {
+	some name in ["b.go", "a.go", "gone.go"]
+	violation := {
+		"message": sprintf("in %s", [name]),
+		"position": {"file": name, "line": 1, "column": 1},
+		"rule": "PKG999",
+	}
+}
+`})
+	if err != nil {
+		t.Fatalf("evaluator.New() error = %v", err)
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	var analyzed []analyzedFile
+	for _, name := range []string{"a.go", "b.go"} {
+		file, err := parser.ParseFile(fset, name, "package p\n", parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+		analyzed = append(analyzed, analyzedFile{file: file, codeCtx: &model.CodeContext{
+			FilePath: name,
+			Package:  model.PackageInfo{Name: "p", Path: "example.com/p"},
+		}})
+	}
+
+	var got []string
+	pass := &analysis.Pass{
+		Fset:  fset,
+		Files: files,
+		Pkg:   types.NewPackage("example.com/p", "p"),
+		Report: func(d analysis.Diagnostic) {
+			got = append(got, fset.Position(d.Pos).Filename+": "+d.Message)
+		},
+	}
+	fixPkg := &fix.Package{Fset: fset, Files: files}
+
+	if err := reportPackage(pass, config.Default(), eval, fixPkg, analyzed); err != nil {
+		t.Fatalf("reportPackage() error = %v", err)
+	}
+
+	want := []string{
+		"a.go: [PKG999] in a.go",
+		"b.go: [PKG999] in b.go",
+		"a.go: [PKG999] in gone.go",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("diagnostics = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,23 @@
+package regolint.rules.directives.linkname
+
+metadata := {
+	"id": "DIR001",
+	"severity": "error",
+	"description": "Restricts go:linkname to allow-listed packages",
+}
+
+allowed_packages := set()
+
+deny contains violation if {
+	not input.package.path in allowed_packages
+
+	some d in input.directives
+	d.name == "go:linkname"
+
+	violation := {
+		"message": sprintf("go:linkname %s is not allowed in package '%s'", [concat(" ", d.args), input.package.path]),
+		"position": d.position,
+		"rule": metadata.id,
+		"severity": metadata.severity,
+	}
+}
@@ -0,0 +1,28 @@
+package regolint.rules.directives.linkname_test
+
+import data.regolint.rules.directives.linkname
+
+test_detects_linkname if {
+	violations := linkname.deny with input as {
+		"package": {"path": "github.com/example/app/clock"},
+		"directives": [{"name": "go:linkname", "args": ["nanotime", "runtime.nanotime"], "position": {"line": 9}}],
+	}
+	count(violations) == 1
+}
+
+test_allows_allow_listed_package if {
+	violations := linkname.deny with input as {
+		"package": {"path": "github.com/example/app/clock"},
+		"directives": [{"name": "go:linkname", "args": ["nanotime", "runtime.nanotime"], "position": {"line": 9}}],
+	}
+		with linkname.allowed_packages as {"github.com/example/app/clock"}
+	count(violations) == 0
+}
+
+test_ignores_other_directives if {
+	violations := linkname.deny with input as {
+		"package": {"path": "github.com/example/app/clock"},
+		"directives": [{"name": "go:noinline", "args": [], "position": {"line": 9}}],
+	}
+	count(violations) == 0
+}
@@ -0,0 +1,28 @@
+package regolint.rules.directives.mocks
+
+metadata := {
+	"id": "DIR002",
+	"severity": "warning",
+	"description": "Requires a go:generate directive in packages declaring mocks",
+}
+
+deny_package contains violation if {
+	not has_generate
+
+	some t in input.all_types
+	startswith(t.name, "Mock")
+
+	violation := {
+		"message": sprintf("Mock '%s' has no go:generate directive in its package to regenerate it", [t.name]),
+		"position": t.position,
+		"rule": metadata.id,
+		"severity": metadata.severity,
+	}
+}
+
+# has_generate holds if any file of the package has a go:generate directive.
+has_generate if {
+	some f in input.files
+	some d in f.directives
+	d.name == "go:generate"
+}
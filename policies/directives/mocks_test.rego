@@ -0,0 +1,30 @@
+package regolint.rules.directives.mocks_test
+
+import data.regolint.rules.directives.mocks
+
+test_detects_mock_without_generate if {
+	violations := mocks.deny_package with input as {
+		"files": [{"generated": {"generator": "MockGen"}}],
+		"all_types": [{"name": "MockStore", "position": {"line": 12}}],
+	}
+	count(violations) == 1
+}
+
+test_allows_mock_with_generate if {
+	violations := mocks.deny_package with input as {
+		"files": [
+			{"directives": [{"name": "go:generate", "args": ["mockgen", "-destination", "mock_store.go", ".", "Store"]}]},
+			{"generated": {"generator": "MockGen"}},
+		],
+		"all_types": [{"name": "MockStore", "position": {"line": 12}}],
+	}
+	count(violations) == 0
+}
+
+test_ignores_other_types if {
+	violations := mocks.deny_package with input as {
+		"files": [{}],
+		"all_types": [{"name": "Store", "position": {"line": 12}}],
+	}
+	count(violations) == 0
+}
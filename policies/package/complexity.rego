@@ -9,7 +9,7 @@ metadata := {
 max_complexity := 15
 max_function_lines := 50
 
-deny contains violation if {
+deny_package contains violation if {
 	some fn in input.all_functions
 	fn.complexity > max_complexity
 
@@ -22,7 +22,7 @@ deny contains violation if {
 	}
 }
 
-deny contains violation if {
+deny_package contains violation if {
 	some fn in input.all_functions
 	fn.line_count > max_function_lines
 
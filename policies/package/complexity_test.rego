@@ -3,7 +3,7 @@ package regolint.rules.package.complexity_test
 import data.regolint.rules.package.complexity
 
 test_detects_high_complexity if {
-	violations := complexity.deny with input as {"all_functions": [{
+	violations := complexity.deny_package with input as {"all_functions": [{
 		"name": "complexFunc",
 		"complexity": 20,
 		"line_count": 30,
@@ -13,7 +13,7 @@ test_detects_high_complexity if {
 }
 
 test_allows_normal_complexity if {
-	violations := complexity.deny with input as {"all_functions": [{
+	violations := complexity.deny_package with input as {"all_functions": [{
 		"name": "simpleFunc",
 		"complexity": 5,
 		"line_count": 20,
@@ -23,7 +23,7 @@ test_allows_normal_complexity if {
 }
 
 test_detects_long_function if {
-	violations := complexity.deny with input as {"all_functions": [{
+	violations := complexity.deny_package with input as {"all_functions": [{
 		"name": "longFunc",
 		"complexity": 5,
 		"line_count": 100,
@@ -33,7 +33,7 @@ test_detects_long_function if {
 }
 
 test_detects_both_issues if {
-	violations := complexity.deny with input as {"all_functions": [{
+	violations := complexity.deny_package with input as {"all_functions": [{
 		"name": "badFunc",
 		"complexity": 20,
 		"line_count": 100,
@@ -6,17 +6,10 @@ metadata := {
 	"description": "Checks that exported types have documentation",
 }
 
-# Support both file context (types) and package context (all_types)
-types_to_check := input.all_types if input.all_types
-types_to_check := input.types if not input.all_types
-
-functions_to_check := input.all_functions if input.all_functions
-functions_to_check := input.functions if not input.all_functions
-
-deny contains violation if {
-	some t in types_to_check
+deny_package contains violation if {
+	some t in input.all_types
 	t.is_exported
-	t.doc == ""
+	object.get(t, "doc", "") == ""
 
 	violation := {
 		"message": sprintf("Exported type '%s' should have documentation", [t.name]),
@@ -27,11 +20,11 @@ deny contains violation if {
 	}
 }
 
-deny contains violation if {
-	some fn in functions_to_check
+deny_package contains violation if {
+	some fn in input.all_functions
 	fn.is_exported
 	not fn.is_test
-	count(fn.comments) == 0
+	count(object.get(fn, "comments", [])) == 0
 
 	violation := {
 		"message": sprintf("Exported function '%s' should have documentation", [fn.name]),
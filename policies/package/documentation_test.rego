@@ -3,7 +3,7 @@ package regolint.rules.package.documentation_test
 import data.regolint.rules.package.documentation
 
 test_detects_undocumented_exported_type if {
-	violations := documentation.deny with input as {
+	violations := documentation.deny_package with input as {
 		"all_types": [{
 			"name": "User",
 			"is_exported": true,
@@ -16,7 +16,7 @@ test_detects_undocumented_exported_type if {
 }
 
 test_allows_documented_exported_type if {
-	violations := documentation.deny with input as {
+	violations := documentation.deny_package with input as {
 		"all_types": [{
 			"name": "User",
 			"is_exported": true,
@@ -29,7 +29,7 @@ test_allows_documented_exported_type if {
 }
 
 test_ignores_unexported_types if {
-	violations := documentation.deny with input as {
+	violations := documentation.deny_package with input as {
 		"all_types": [{
 			"name": "user",
 			"is_exported": false,
@@ -42,7 +42,7 @@ test_ignores_unexported_types if {
 }
 
 test_detects_undocumented_exported_function if {
-	violations := documentation.deny with input as {
+	violations := documentation.deny_package with input as {
 		"all_types": [],
 		"all_functions": [{
 			"name": "GetUser",
@@ -56,7 +56,7 @@ test_detects_undocumented_exported_function if {
 }
 
 test_allows_documented_exported_function if {
-	violations := documentation.deny with input as {
+	violations := documentation.deny_package with input as {
 		"all_types": [],
 		"all_functions": [{
 			"name": "GetUser",
@@ -70,7 +70,7 @@ test_allows_documented_exported_function if {
 }
 
 test_ignores_test_functions if {
-	violations := documentation.deny with input as {
+	violations := documentation.deny_package with input as {
 		"all_types": [],
 		"all_functions": [{
 			"name": "TestGetUser",
@@ -82,3 +82,26 @@ test_ignores_test_functions if {
 	}
 	count(violations) == 0
 }
+
+test_fix_describes_doc_comment if {
+	violations := documentation.deny_package with input as {
+		"all_types": [],
+		"all_functions": [{
+			"name": "Run",
+			"is_exported": true,
+			"is_test": false,
+			"comments": [],
+			"position": {"file": "run.go", "line": 7, "column": 1},
+		}],
+	}
+	some v in violations
+	v.fix == {"description": "Add a doc comment above function Run"}
+}
+
+test_detects_omitted_doc_fields if {
+	violations := documentation.deny_package with input as {
+		"all_types": [{"name": "User", "is_exported": true, "position": {"line": 3}}],
+		"all_functions": [{"name": "Run", "is_exported": true, "is_test": false, "position": {"line": 7}}],
+	}
+	count(violations) == 2
+}
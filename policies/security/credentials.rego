@@ -28,7 +28,7 @@ deny contains violation if {
 
 deny contains violation if {
 	some v in input.variables
-	v.in_function == ""
+	object.get(v, "in_function", "") == ""
 
 	name_lower := lower(v.name)
 	some pattern in sensitive_patterns
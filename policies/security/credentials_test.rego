@@ -45,6 +45,14 @@ test_detects_package_level_password_var if {
 	count(violations) == 1
 }
 
+test_detects_password_var_without_in_function if {
+	violations := credentials.deny with input as {
+		"constants": [],
+		"variables": [{"name": "password", "position": {"line": 10}}],
+	}
+	count(violations) == 1
+}
+
 test_ignores_local_password_var if {
 	violations := credentials.deny with input as {
 		"constants": [],
@@ -18,13 +18,30 @@ deny contains violation if {
 	not field.is_embedded
 
 	some tag in required_tags
-	field_tags := object.get(field, "tags", "")
-	not contains(field_tags, concat("", [tag, ":"]))
+	not has_tag(field, tag)
 
 	violation := {
 		"message": sprintf("Exported field '%s.%s' missing required '%s' tag", [t.name, field.name, tag]),
 		"position": field.position,
 		"rule": metadata.id,
 		"severity": metadata.severity,
+		"fix": {
+			"description": sprintf("Add a %s tag to %s.%s", [tag, t.name, field.name]),
+			"ops": [{
+				"op": "add_struct_tag",
+				"position": field.position,
+				"key": tag,
+				"value": snake_case(field.name),
+			}],
+		},
 	}
 }
+
+# has_tag holds if the parsed tags of field include key.
+has_tag(field, key) if field.tag_map[key]
+
+# snake_case converts a Go identifier such as HTTPServerID to http_server_id.
+snake_case(name) := lower(regex.replace(
+	regex.replace(name, `([A-Z]+)([A-Z][a-z])`, "${1}_${2}"),
+	`([a-z0-9])([A-Z])`, "${1}_${2}",
+))
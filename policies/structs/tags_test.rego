@@ -45,6 +45,43 @@ test_allows_field_with_json_tag if {
 			"is_exported": true,
 			"is_embedded": false,
 			"tags": "json:\"name\"",
+			"tag_map": {"json": {"value": "name", "name": "name", "options": []}},
+			"position": {"line": 5},
+		}],
+		"position": {"line": 4},
+	}]}
+	count(violations) == 0
+}
+
+test_detects_key_ending_in_json if {
+	violations := tags.deny with input as {"types": [{
+		"name": "User",
+		"kind": "struct",
+		"is_exported": true,
+		"fields": [{
+			"name": "Name",
+			"is_exported": true,
+			"is_embedded": false,
+			"tags": "xjson:\"name\"",
+			"tag_map": {"xjson": {"value": "name", "name": "name", "options": []}},
+			"position": {"line": 5},
+		}],
+		"position": {"line": 4},
+	}]}
+	count(violations) == 1
+}
+
+test_allows_json_tag_with_only_options if {
+	violations := tags.deny with input as {"types": [{
+		"name": "User",
+		"kind": "struct",
+		"is_exported": true,
+		"fields": [{
+			"name": "Name",
+			"is_exported": true,
+			"is_embedded": false,
+			"tags": "json:\",omitempty\"",
+			"tag_map": {"json": {"value": ",omitempty", "name": "", "options": ["omitempty"]}},
 			"position": {"line": 5},
 		}],
 		"position": {"line": 4},
@@ -102,3 +139,32 @@ test_ignores_embedded_fields if {
 	}]}
 	count(violations) == 0
 }
+
+test_fix_adds_snake_case_tag if {
+	violations := tags.deny with input as {"types": [{
+		"name": "Server",
+		"kind": "struct",
+		"is_exported": true,
+		"fields": [{
+			"name": "HTTPServerID",
+			"is_exported": true,
+			"is_embedded": false,
+			"position": {"line": 5, "column": 2},
+		}],
+		"position": {"line": 4},
+	}]}
+	some v in violations
+	v.fix.ops == [{
+		"op": "add_struct_tag",
+		"position": {"line": 5, "column": 2},
+		"key": "json",
+		"value": "http_server_id",
+	}]
+}
+
+test_snake_case if {
+	tags.snake_case("Name") == "name"
+	tags.snake_case("UserID") == "user_id"
+	tags.snake_case("HTTPServer") == "http_server"
+	tags.snake_case("Field2Name") == "field2_name"
+}